@@ -8,8 +8,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,7 +29,13 @@ func (e Error) Error() string {
 	return string(e)
 }
 
-const ErrTimedOut Error = "app timed out"
+// The number of bytes of output kept to show why the app server exited.
+const appOutputTail = 16 * 1024
+
+const (
+	ErrTimedOut         Error = "app timed out"
+	ErrDebuggerNotFound Error = "dlv was not found in the PATH, install it with 'go install github.com/go-delve/delve/cmd/dlv@latest'"
+)
 
 // App contains the configuration for running a Revel app.  (Not for the app itself)
 // Its only purpose is constructing the command to execute.
@@ -36,6 +45,10 @@ type App struct {
 	cmd            AppCmd            // The last cmd returned.
 	PackagePathMap map[string]string // Package to directory path map
 	Paths          *model.RevelContainer
+	Debugger       string // Path to the dlv executable, the app runs under the debugger if set
+	DebugAddr      string // The address the debugger listens on
+	PprofAddr      string // The address of the net/http/pprof server of the app, if it has one
+	CoverDir       string // The folder the coverage data is written to, for an app built with -cover
 }
 
 // NewApp returns app instance with binary path in it.
@@ -45,46 +58,142 @@ func NewApp(binPath string, paths *model.RevelContainer, packagePathMap map[stri
 
 // Cmd returns a command to run the app server using the current configuration.
 func (a *App) Cmd(runMode string) AppCmd {
-	a.cmd = NewAppCmd(a.BinaryPath, a.Port, runMode, a.Paths)
+	if a.Debugger != "" {
+		a.cmd = NewDebugAppCmd(a.Debugger, a.DebugAddr, a.BinaryPath, a.Port, runMode, a.Paths)
+	} else {
+		a.cmd = NewAppCmd(a.BinaryPath, a.Port, runMode, a.Paths)
+	}
+	if a.PprofAddr != "" {
+		a.cmd.Args = append(a.cmd.Args, "-pprofAddr="+a.PprofAddr)
+	}
+	a.cmd.CoverDir = a.CoverDir
 	return a.cmd
 }
 
+// Cover writes the coverage data of the app to the GOCOVERDIR folder, or else to the
+// test-results/coverage folder of the application, like revel test does.
+func (a *App) Cover() error {
+	a.CoverDir = os.Getenv("GOCOVERDIR")
+	if a.CoverDir == "" {
+		a.CoverDir = filepath.Join(a.Paths.BasePath, "test-results", "coverage")
+	}
+	if err := os.MkdirAll(a.CoverDir, 0777); err != nil {
+		return utils.NewBuildIfError(err, "Failed to create coverage directory", "path", a.CoverDir)
+	}
+	return nil
+}
+
+// Debug runs the app under the Delve debugger, which listens on the address.
+func (a *App) Debug(addr string) (err error) {
+	a.Debugger, err = FindDebugger()
+	a.DebugAddr = addr
+	return
+}
+
+// FindDebugger returns the path of the dlv executable.
+func FindDebugger() (string, error) {
+	dlv, err := exec.LookPath("dlv")
+	if err != nil {
+		return "", ErrDebuggerNotFound
+	}
+	return dlv, nil
+}
+
 // Kill the last app command returned.
 func (a *App) Kill() {
 	a.cmd.Kill()
 }
 
+// Exited returns a channel which is closed when the app server exits after it started.
+func (a *App) Exited() <-chan struct{} {
+	if a.cmd.exit == nil {
+		return nil
+	}
+	return a.cmd.exit.done
+}
+
+// ExitError returns the error of an app server which exited on its own with its last output,
+// nil if it was killed.
+func (a *App) ExitError() *utils.SourceError {
+	if a.cmd.exit == nil || atomic.LoadInt32(&a.cmd.exit.killed) == 1 {
+		return nil
+	}
+	return &utils.SourceError{
+		Title: "App exited",
+		Description: "The application exited after it started (" + a.cmd.exit.state +
+			"), it is rebuilt on the next request. The last output is below, see the terminal for all of it.",
+		Stack: string(a.cmd.exit.output),
+	}
+}
+
 // AppCmd manages the running of a Revel app server.
 // It requires revel.Init to have been called previously.
 type AppCmd struct {
 	*exec.Cmd
+	CoverDir string   // The folder the coverage data is written to, for an app built with -cover
+	exit     *appExit // How the app server exited after it started
+	paths    *model.RevelContainer
+	addr     string // The address the app server listens on
+}
+
+// The exit of an app server after it started.
+type appExit struct {
+	done   chan struct{} // Closed when the app server exits
+	killed int32         // Set when the app server is killed
+	state  string        // The exit state
+	output []byte        // The last output of the app server
 }
 
 // NewAppCmd returns the AppCmd with parameters initialized for running app.
 func NewAppCmd(binPath string, port int, runMode string, paths *model.RevelContainer) AppCmd {
-	cmd := exec.Command(binPath,
+	cmd := exec.Command(binPath, appArgs(port, runMode, paths)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return AppCmd{Cmd: cmd, exit: &appExit{done: make(chan struct{})}, paths: paths, addr: appAddr(port, paths)}
+}
+
+// NewDebugAppCmd returns the AppCmd which runs the app under a headless Delve debugger. The
+// app is continued on start, so it serves requests until a client sets a breakpoint, and more
+// than one client may connect. Interrupting the debugger stops the app.
+func NewDebugAppCmd(dlvPath, addr, binPath string, port int, runMode string, paths *model.RevelContainer) AppCmd {
+	args := []string{"exec", binPath, "--headless", "--listen=" + addr, "--api-version=2", "--accept-multiclient", "--continue", "--"}
+	cmd := exec.Command(dlvPath, append(args, appArgs(port, runMode, paths)...)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return AppCmd{Cmd: cmd, exit: &appExit{done: make(chan struct{})}, paths: paths, addr: appAddr(port, paths)}
+}
+
+// Returns the command line arguments of the app.
+func appArgs(port int, runMode string, paths *model.RevelContainer) []string {
+	return []string{
 		fmt.Sprintf("-port=%d", port),
 		fmt.Sprintf("-importPath=%s", paths.ImportPath),
-		fmt.Sprintf("-runMode=%s", runMode))
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return AppCmd{cmd}
+		fmt.Sprintf("-runMode=%s", runMode),
+	}
+}
+
+// Returns the address the app listens on.
+func appAddr(port int, paths *model.RevelContainer) string {
+	return net.JoinHostPort(paths.HTTPAddr, strconv.Itoa(port))
 }
 
 // Start the app server, and wait until it is ready to serve requests.
 func (cmd AppCmd) Start(c *model.CommandConfig) error {
-	listeningWriter := &startupListeningWriter{os.Stdout, make(chan bool), c, &bytes.Buffer{}}
+	listeningWriter := &startupListeningWriter{os.Stdout, make(chan bool), c, &bytes.Buffer{}, &bytes.Buffer{}}
 	cmd.Stdout = listeningWriter
 	cmd.Stderr = listeningWriter
 	utils.CmdInit(cmd.Cmd, !c.Vendored, c.AppPath)
+	if cmd.CoverDir != "" {
+		cmd.Env = append(cmd.Env, "GOCOVERDIR="+cmd.CoverDir)
+	}
 	utils.Logger.Info("Exec app:", "path", cmd.Path, "args", cmd.Args, "dir", cmd.Dir, "env", cmd.Env)
 	if err := cmd.Cmd.Start(); err != nil {
 		utils.Logger.Fatal("Error running:", "error", err)
 	}
 
+	exited := cmd.waitChan()
 	select {
-	case exitState := <-cmd.waitChan():
+	case exitState := <-exited:
 		fmt.Println("Startup failure view previous messages, \n Proxy is listening :", c.Run.Port)
-		err := utils.NewError("", "Revel Run Error", "starting your application there was an exception. See terminal output, "+exitState, "")
+		err := utils.NewError("", "Revel Run Error", "", "Starting your application there was an exception. See terminal output, "+exitState)
 		atomic.SwapInt32(&startupError, 1)
 		// TODO pretiffy command line output
 		err.Stack = listeningWriter.buffer.String()
@@ -99,6 +208,20 @@ func (cmd AppCmd) Start(c *model.CommandConfig) error {
 
 	case <-listeningWriter.notifyReady:
 		println("Revel proxy is listening, point your browser to :", c.Run.Port)
+		if cmd.exit != nil {
+			go func() {
+				cmd.exit.state = <-exited
+				cmd.exit.output = listeningWriter.tail.Bytes()
+				close(cmd.exit.done)
+			}()
+		}
+		if cmd.paths != nil {
+			// The app is stopped if the hook fails, so it is not left running without a watcher
+			if err := RunHooks(cmd.paths, model.APP_STARTED, cmd.addr); err != nil {
+				cmd.Kill()
+				return err
+			}
+		}
 		return nil
 	}
 }
@@ -114,6 +237,9 @@ func (cmd AppCmd) Run(c *model.CommandConfig) {
 
 // Kill terminates the app server if it's running.
 func (cmd AppCmd) Kill() {
+	if cmd.exit != nil {
+		atomic.StoreInt32(&cmd.exit.killed, 1)
+	}
 	if cmd.Cmd != nil && (cmd.ProcessState == nil || !cmd.ProcessState.Exited()) {
 		// Windows appears to send the kill to all threads, shutting down the
 		// server before this can, this check will ensure the process is still running
@@ -160,6 +286,7 @@ func (cmd AppCmd) Kill() {
 		utils.Logger.Info("Waiting to exit")
 		select {
 		case <-ch:
+			cmd.waitForCoverage()
 			return
 		case <-time.After(60 * time.Second):
 			// Kill the process
@@ -173,6 +300,21 @@ func (cmd AppCmd) Kill() {
 	}
 }
 
+// Waits for the coverage data the app writes on exit, the counters file is the last one written.
+// The folder may hold the data of the previous runs, the file names contain the pid.
+func (cmd AppCmd) waitForCoverage() {
+	if cmd.CoverDir == "" {
+		return
+	}
+	pattern := filepath.Join(cmd.CoverDir, fmt.Sprintf("covcounters.*.%d.*", cmd.Process.Pid))
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		if counters, _ := filepath.Glob(pattern); len(counters) > 0 {
+			return
+		}
+	}
+	utils.Logger.Warn("The app did not write its coverage data", "dir", cmd.CoverDir)
+}
+
 // Return a channel that is notified when Wait() returns.
 func (cmd AppCmd) waitChan() <-chan string {
 	ch := make(chan string, 1)
@@ -196,7 +338,8 @@ type startupListeningWriter struct {
 	dest        io.Writer
 	notifyReady chan bool
 	c           *model.CommandConfig
-	buffer      *bytes.Buffer
+	buffer      *bytes.Buffer // The output until the server is ready
+	tail        *bytes.Buffer // The last output after the server is ready
 }
 
 // Writes to this output stream.
@@ -213,6 +356,11 @@ func (w *startupListeningWriter) Write(p []byte) (int, error) {
 	}
 	if w.notifyReady != nil {
 		w.buffer.Write(p)
+	} else {
+		w.tail.Write(p)
+		if w.tail.Len() > 2*appOutputTail {
+			w.tail.Next(w.tail.Len() - appOutputTail)
+		}
 	}
 	return w.dest.Write(p)
 }
@@ -0,0 +1,24 @@
+package harness_test
+
+import (
+	"testing"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the app is run by a headless debugger which continues it, with the arguments of the app
+// after the separator.
+func TestNewDebugAppCmd(t *testing.T) {
+	paths := &model.RevelContainer{ImportPath: "example.com/web", HTTPAddr: "localhost"}
+	cmd := harness.NewDebugAppCmd("/usr/bin/dlv", "localhost:2345", "/tmp/web", 9000, "dev", paths)
+	assert.Equal(t, []string{
+		"/usr/bin/dlv", "exec", "/tmp/web",
+		"--headless", "--listen=localhost:2345", "--api-version=2", "--accept-multiclient", "--continue",
+		"--", "-port=9000", "-importPath=example.com/web", "-runMode=dev",
+	}, cmd.Args)
+
+	// The app receives the same arguments without the debugger
+	assert.Equal(t, cmd.Args[9:], harness.NewAppCmd("/tmp/web", 9000, "dev", paths).Args[1:])
+}
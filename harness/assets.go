@@ -0,0 +1,152 @@
+package harness
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/revel/cmd/utils"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// AssetsManifest is the file which maps the path of each asset to the path it is served from,
+// e.g. "css/app.css" to "css/app.5d41402a.css". revel build writes it in the public folder, revel
+// run in app/tmp.
+const AssetsManifest = "assets-manifest.json"
+
+// The media types of the assets which are minified, by extension.
+var minifiedAssets = map[string]string{
+	".css": "text/css",
+	".js":  "application/javascript",
+}
+
+// The extensions of the assets which are precompressed.
+var compressedAssets = map[string]bool{
+	".css": true, ".js": true, ".json": true, ".map": true, ".svg": true,
+	".html": true, ".txt": true, ".xml": true, ".ico": true,
+}
+
+// BuildAssets writes the assets manifest of the public folder to the manifest path. When
+// fingerprint is set a copy of every asset is named with the hash of its content, the CSS and JS
+// copies are minified, and the compressible ones get .gz and .br siblings, so the app can serve
+// them with a long cache lifetime. The assets themselves are left as they are. Otherwise the manifest maps every asset to itself, as the dev
+// harness serves them. Nothing is written if the folder does not exist.
+func BuildAssets(publicPath, manifestPath string, fingerprint bool) (manifest map[string]string, err error) {
+	if !utils.DirExists(publicPath) {
+		return nil, nil
+	}
+
+	// The copies of a previous build are not assets of their own
+	copies := map[string]bool{}
+	if content, err := ioutil.ReadFile(manifestPath); err == nil {
+		previous := map[string]string{}
+		_ = json.Unmarshal(content, &previous)
+		for logical, hashed := range previous {
+			if hashed != logical {
+				copies[filepath.Join(publicPath, filepath.FromSlash(hashed))] = true
+			}
+		}
+	}
+
+	var files []string
+	err = filepath.Walk(publicPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if info.IsDir() || ext == ".gz" || ext == ".br" || filepath.Join(publicPath, AssetsManifest) == path || copies[path] {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to read the assets", "path", publicPath)
+	}
+
+	minifier := minify.New()
+	minifier.AddFunc("text/css", css.Minify)
+	minifier.AddFunc("application/javascript", js.Minify)
+
+	manifest = map[string]string{}
+	for _, path := range files {
+		relative, _ := filepath.Rel(publicPath, path)
+		logical := filepath.ToSlash(relative)
+		if !fingerprint {
+			manifest[logical] = logical
+			continue
+		}
+		hashed, err := buildAsset(minifier, path)
+		if err != nil {
+			return nil, err
+		}
+		manifest[logical] = filepath.ToSlash(filepath.Join(filepath.Dir(relative), hashed))
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(manifestPath), 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(manifestPath, append(content, '\n'), 0644)
+	}
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to write the assets manifest", "path", manifestPath)
+	}
+	return manifest, nil
+}
+
+// Writes the minified and compressed fingerprinted copy of the asset. The name of the copy is
+// returned.
+func buildAsset(minifier *minify.M, path string) (hashed string, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", utils.NewBuildIfError(err, "Failed to read the asset", "path", path)
+	}
+	name, ext := filepath.Base(path), filepath.Ext(path)
+	if mediaType, found := minifiedAssets[ext]; found && !strings.Contains(name, ".min.") {
+		if content, err = minifier.Bytes(mediaType, content); err != nil {
+			return "", utils.NewBuildIfError(err, "Failed to minify the asset", "path", path)
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	hashed = strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:4]) + ext
+	file := filepath.Join(filepath.Dir(path), hashed)
+	if err = ioutil.WriteFile(file, content, 0644); err == nil && compressedAssets[ext] {
+		err = compressAsset(file, content)
+	}
+	if err != nil {
+		return "", utils.NewBuildIfError(err, "Failed to write the asset", "path", file)
+	}
+	return hashed, nil
+}
+
+// Writes the gzip and brotli compressed content next to the asset.
+func compressAsset(path string, content []byte) (err error) {
+	gzipped, compressed := &bytes.Buffer{}, &bytes.Buffer{}
+	gzipWriter, _ := gzip.NewWriterLevel(gzipped, gzip.BestCompression)
+	brotliWriter := brotli.NewWriterLevel(compressed, brotli.BestCompression)
+	for _, writer := range []io.WriteCloser{gzipWriter, brotliWriter} {
+		if _, err = writer.Write(content); err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			return
+		}
+	}
+	if err = ioutil.WriteFile(path+".gz", gzipped.Bytes(), 0644); err != nil {
+		return
+	}
+	return ioutil.WriteFile(path+".br", compressed.Bytes(), 0644)
+}
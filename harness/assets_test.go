@@ -0,0 +1,120 @@
+package harness_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates a public folder with the assets, and returns it with the folder of the manifest.
+func newAssets(t *testing.T, assets map[string]string) (publicPath, tmpPath string) {
+	basePath, err := ioutil.TempDir("", "revel-assets")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(basePath) })
+	publicPath, tmpPath = filepath.Join(basePath, "public"), filepath.Join(basePath, "app", "tmp")
+	for name, content := range assets {
+		path := filepath.Join(publicPath, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return
+}
+
+// Returns the name of the copy of the asset with the hash of the content.
+func hashedAsset(name, ext, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return name + "." + hex.EncodeToString(sum[:4]) + ext
+}
+
+func readAsset(t *testing.T, publicPath, name string) string {
+	content, err := ioutil.ReadFile(filepath.Join(publicPath, filepath.FromSlash(name)))
+	assert.Nil(t, err, name)
+	return string(content)
+}
+
+func TestBuildAssetsMissing(t *testing.T) {
+	publicPath, tmpPath := newAssets(t, nil)
+	manifest, err := harness.BuildAssets(publicPath, filepath.Join(tmpPath, harness.AssetsManifest), true)
+	assert.Nil(t, err)
+	assert.Nil(t, manifest)
+	assert.False(t, utils.Exists(tmpPath))
+}
+
+// Test that the run manifest maps the assets to themselves and leaves the public folder alone.
+func TestBuildAssetsRun(t *testing.T) {
+	publicPath, tmpPath := newAssets(t, map[string]string{
+		"css/app.css":          "body {  color: red;  }",
+		"js/app.js":            "var a = 1;",
+		"css/app.css.gz":       "stale",
+		harness.AssetsManifest: "{}",
+		"img/logo.png":         "png",
+	})
+	manifestPath := filepath.Join(tmpPath, harness.AssetsManifest)
+	manifest, err := harness.BuildAssets(publicPath, manifestPath, false)
+	assert.Nil(t, err)
+	expected := map[string]string{"css/app.css": "css/app.css", "js/app.js": "js/app.js", "img/logo.png": "img/logo.png"}
+	assert.Equal(t, expected, manifest)
+
+	written := map[string]string{}
+	content, err := ioutil.ReadFile(manifestPath)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(content, &written))
+	assert.Equal(t, expected, written)
+
+	assert.Equal(t, "{}", readAsset(t, publicPath, harness.AssetsManifest))
+	assert.Equal(t, "body {  color: red;  }", readAsset(t, publicPath, "css/app.css"))
+	assert.False(t, utils.Exists(filepath.Join(publicPath, "js", "app.js.gz")))
+}
+
+func TestBuildAssetsFingerprint(t *testing.T) {
+	publicPath, _ := newAssets(t, map[string]string{
+		"css/app.css":   "body {\n  color: red;\n}\n",
+		"js/app.js":     "function add(first, second) {\n  return first + second;\n}\n",
+		"js/lib.min.js": "var a = 1 ;",
+		"img/logo.png":  "png",
+	})
+	manifestPath := filepath.Join(publicPath, harness.AssetsManifest)
+	manifest, err := harness.BuildAssets(publicPath, manifestPath, true)
+	assert.Nil(t, err)
+
+	// The copies of the CSS and JS files are minified, except the minified ones
+	css := "body{color:red}"
+	assert.Equal(t, "body {\n  color: red;\n}\n", readAsset(t, publicPath, "css/app.css"))
+	assert.Equal(t, "css/"+hashedAsset("app", ".css", css), manifest["css/app.css"])
+	assert.Equal(t, css, readAsset(t, publicPath, manifest["css/app.css"]))
+	assert.NotContains(t, readAsset(t, publicPath, manifest["js/app.js"]), "\n")
+	assert.Equal(t, "js/"+hashedAsset("lib.min", ".js", "var a = 1 ;"), manifest["js/lib.min.js"])
+	assert.Equal(t, "img/"+hashedAsset("logo", ".png", "png"), manifest["img/logo.png"])
+	assert.Len(t, manifest, 4)
+
+	// The copies of the compressible assets get a .gz and .br sibling which decompress to the copy
+	name := manifest["css/app.css"]
+	gzipReader, err := gzip.NewReader(bytes.NewReader([]byte(readAsset(t, publicPath, name+".gz"))))
+	if assert.Nil(t, err) {
+		content, err := ioutil.ReadAll(gzipReader)
+		assert.Nil(t, err)
+		assert.Equal(t, css, string(content))
+	}
+	content, err := ioutil.ReadAll(brotli.NewReader(bytes.NewReader([]byte(readAsset(t, publicPath, name+".br")))))
+	assert.Nil(t, err)
+	assert.Equal(t, css, string(content))
+	assert.False(t, utils.Exists(filepath.Join(publicPath, "css", "app.css.gz")))
+	assert.False(t, utils.Exists(filepath.Join(publicPath, "css", "app.css.br")))
+	assert.False(t, utils.Exists(filepath.Join(publicPath, "img", "logo.png.gz")))
+
+	// A second build skips the manifest and the compressed files, and gives the same names
+	again, err := harness.BuildAssets(publicPath, manifestPath, true)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, again)
+}
@@ -26,6 +26,7 @@ import (
 
 var importErrorPattern = regexp.MustCompile("cannot find package \"([^\"]+)\"")
 var importErrorPattern2 = regexp.MustCompile("no required module provides package ([^;]+)+")
+var importErrorPattern3 = regexp.MustCompile(`cannot find module providing package ([^:\s]+)`)
 var addPackagePattern = regexp.MustCompile(`to add:\n\tgo get (.*)\n`)
 
 type ByString []*model.TypeInfo
@@ -48,19 +49,23 @@ func (c ByString) Less(i, j int) bool {
 // Requires that revel.Init has been called previously.
 // Returns the path to the built binary, and an error if there was a problem building it.
 func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err error) {
+	if err = RunHooks(paths, model.BUILD_STARTED, paths); err != nil {
+		return
+	}
+
 	// First, clear the generated files (to avoid them messing with ProcessSource).
 	cleanSource(paths, "tmp", "routes")
 
-	var sourceInfo *model.SourceInfo
-
-	if c.HistoricBuildMode {
-		sourceInfo, err = parser.ProcessSource(paths)
-	} else {
-		sourceInfo, err = parser2.ProcessSource(paths)
-	}
+	sourceInfo, err := ProcessSource(c, paths)
 	if err != nil {
 		return
 	}
+	if err = RunHooks(paths, model.SOURCE_PROCESSED, paths); err != nil {
+		return
+	}
+	if err = CheckTemplates(paths); err != nil {
+		return
+	}
 
 	// Add the db.import to the import paths.
 	if dbImportPath, found := paths.Config.String("db.import"); found {
@@ -78,6 +83,7 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 		"ValidationKeys": sourceInfo.ValidationKeys,
 		"ImportPaths":    calcImportAliases(sourceInfo),
 		"TestSuites":     sourceInfo.TestSuites(),
+		"Pprof":          c.Index == model.RUN && c.Run.Pprof,
 	}
 
 	// Generate code for the main, run and routes file.
@@ -95,6 +101,15 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 		return
 	}
 
+	// The dev harness serves the assets as they are, the manifest only has the shape of the build.
+	// It is written with the generated files, so the source of the app is left alone
+	if c.Index == model.RUN && paths.Config.BoolDefault("build.assets", false) {
+		manifestPath := filepath.Join(paths.AppPath, "tmp", AssetsManifest)
+		if _, err = BuildAssets(filepath.Join(paths.BasePath, "public"), manifestPath, false); err != nil {
+			return
+		}
+	}
+
 	// Read build config.
 	buildTags := paths.Config.StringDefault("build.tags", "")
 
@@ -127,7 +142,9 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 		return false
 	}
 
-	if len(c.GoModFlags) > 0 {
+	if len(c.GoModFlags) > 0 && c.Offline {
+		utils.Logger.Warn("Offline mode, skipping the go mod commands", "gomod-flags", c.GoModFlags)
+	} else if len(c.GoModFlags) > 0 {
 		for _, gomod := range c.GoModFlags {
 			goModCmd := exec.Command(goPath, append([]string{"mod"}, strings.Split(gomod, " ")...)...)
 			utils.CmdInit(goModCmd, !c.Vendored, c.AppPath)
@@ -180,6 +197,8 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 			}
 		}
 
+		flags = append(flags, instrumentationFlags(c, paths)...)
+
 		// Note: It's not applicable for filepath.* usage
 		flags = append(flags, path.Join(paths.ImportPath, "app", "tmp"))
 
@@ -203,6 +222,9 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 		// If the build succeeded, we're done.
 		if err == nil {
 			utils.Logger.Info("Build successful continuing")
+			if err = RunHooks(paths, model.BUILD_COMPLETED, binName); err != nil {
+				return nil, err
+			}
 			return NewApp(binName, paths, sourceInfo.PackageMap), nil
 		}
 
@@ -215,6 +237,9 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 		if matches == nil {
 			matches = importErrorPattern2.FindAllStringSubmatch(stOutput, -1)
 		}
+		if matches == nil {
+			matches = importErrorPattern3.FindAllStringSubmatch(stOutput, -1)
+		}
 		if matches == nil {
 			matches = addPackagePattern.FindAllStringSubmatch(stOutput, -1)
 
@@ -239,6 +264,13 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 			}
 		}
 
+		if c.Offline {
+			utils.Logger.Error("Detected missing packages, offline mode does not fetch them", "packages", missedPkgs)
+			compileError := newCompileError(paths, output)
+			compileError.Description = model.OfflineError(missedPkgs...).Error()
+			return nil, compileError
+		}
+
 		utils.Logger.Warn("Detected missing packages, importing them", "packages", len(matches))
 		for _, pkgName := range missedPkgs {
 			// Ensure we haven't already tried to go get it.
@@ -262,6 +294,14 @@ func Build(c *model.CommandConfig, paths *model.RevelContainer) (_ *App, err err
 	// unreachable
 }
 
+// ProcessSource scans the application source using the parser selected by the command config.
+func ProcessSource(c *model.CommandConfig, paths *model.RevelContainer) (*model.SourceInfo, error) {
+	if c.HistoricBuildMode {
+		return parser.ProcessSource(paths)
+	}
+	return parser2.ProcessSource(paths)
+}
+
 // Try to define a version string for the compiled app
 // The following is tried (first match returns):
 // - Read a version explicitly specified in the APP_VERSION environment
@@ -353,16 +393,7 @@ func calcImportAliases(src *model.SourceInfo) map[string]string {
 	for _, specs := range typeArrays {
 		for _, spec := range specs {
 			addAlias(aliases, spec.ImportPath, spec.PackageName)
-
-			for _, methSpec := range spec.MethodSpecs {
-				for _, methArg := range methSpec.Args {
-					if methArg.ImportPath == "" {
-						continue
-					}
-
-					addAlias(aliases, methArg.ImportPath, methArg.TypeExpr.PkgName)
-				}
-			}
+			addArgAliases(aliases, spec.MethodSpecs...)
 		}
 	}
 
@@ -376,6 +407,60 @@ func calcImportAliases(src *model.SourceInfo) map[string]string {
 	return aliases
 }
 
+// Adds the aliases for the imported types used as arguments by the methods.
+func addArgAliases(aliases map[string]string, methodSpecs ...*model.MethodSpec) {
+	for _, methSpec := range methodSpecs {
+		for _, methArg := range methSpec.Args {
+			if methArg.ImportPath == "" {
+				continue
+			}
+
+			addAlias(aliases, methArg.ImportPath, methArg.TypeExpr.PkgName)
+		}
+	}
+}
+
+// Returns the build flags of the debugger, the race detector and the coverage.
+func instrumentationFlags(c *model.CommandConfig, paths *model.RevelContainer) (flags []string) {
+	// The debugger needs the optimizations and inlining disabled
+	if c.Index == model.RUN && c.Run.Delve {
+		flags = append(flags, "-gcflags=all=-N -l")
+	}
+	race, cover := c.Instrumentation()
+	if race {
+		flags = append(flags, "-race")
+	}
+	if cover {
+		flags = append(flags, "-cover", "-coverpkg="+coverPackages(c, paths))
+	}
+	return
+}
+
+// Returns the packages of the application measured by the coverage, the generated routes and
+// the test suites are left out. The generated main is measured as well, without it the
+// coverage is not written on exit.
+func coverPackages(c *model.CommandConfig, paths *model.RevelContainer) string {
+	listCmd := exec.Command(c.GoCmd, "list", "-f", "{{.ImportPath}}", paths.ImportPath+"/...")
+	utils.CmdInit(listCmd, !c.Vendored, c.AppPath)
+	output, err := listCmd.Output()
+	if err != nil {
+		utils.Logger.Warn("Failed to list the application packages, measuring all of them", "error", err)
+		return paths.ImportPath + "/..."
+	}
+	packages := []string{}
+	for _, pkg := range strings.Fields(string(output)) {
+		switch name := strings.TrimPrefix(pkg, paths.ImportPath+"/"); {
+		case strings.HasPrefix(name, "app/tmp/"), name == "app/routes", name == "tests", strings.HasPrefix(name, "tests/"):
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+	if len(packages) == 0 {
+		return paths.ImportPath + "/..."
+	}
+	return strings.Join(packages, ",")
+}
+
 // Adds an alias to the map of alias names.
 func addAlias(aliases map[string]string, importPath, pkgName string) {
 	_, ok := aliases[importPath]
@@ -425,7 +510,7 @@ func newCompileError(paths *model.RevelContainer, output []byte) *utils.SourceEr
 
 		errorMatch = append(errorMatch, errorMatch[3])
 
-		utils.Logger.Error("Build errors", "errors", string(output))
+		utils.Logger.Debug("Build errors", "file", string(errorMatch[1]))
 	}
 
 	findInPaths := func(relFilename string) string {
@@ -433,7 +518,6 @@ func newCompileError(paths *model.RevelContainer, output []byte) *utils.SourceEr
 		gopaths := filepath.SplitList(build.Default.GOPATH)
 		for _, gp := range gopaths {
 			newPath := filepath.Join(gp, "src", paths.ImportPath, relFilename)
-			println(newPath)
 			if utils.Exists(newPath) {
 				return newPath
 			}
@@ -448,13 +532,16 @@ func newCompileError(paths *model.RevelContainer, output []byte) *utils.SourceEr
 		relFilename  = string(errorMatch[1]) // e.g. "src/revel/sample/app/controllers/app.go"
 		absFilename  = findInPaths(relFilename)
 		line, _      = strconv.Atoi(string(errorMatch[2]))
+		column, _    = strconv.Atoi(strings.TrimSuffix(string(errorMatch[3]), ":"))
 		description  = string(errorMatch[4])
 		compileError = &utils.SourceError{
 			SourceType:  "Go code",
 			Title:       "Go Compilation Error",
 			Path:        relFilename,
+			AbsPath:     absFilename,
 			Description: description,
 			Line:        line,
+			Column:      column,
 		}
 	)
 
@@ -538,7 +625,10 @@ const RevelMainTemplate = `// GENERATED CODE - DO NOT EDIT
 package main
 
 import (
-	"flag"
+	"flag"{{if .Pprof}}
+	"log"
+	"net/http"
+	_ "net/http/pprof"{{end}}
 	"{{.ImportPath}}/app/tmp/run"
 	"github.com/revel/revel"
 )
@@ -547,12 +637,18 @@ var (
 	runMode    *string = flag.String("runMode", "", "Run mode.")
 	port       *int    = flag.Int("port", 0, "By default, read from app.conf")
 	importPath *string = flag.String("importPath", "", "Go Import Path for the app.")
-	srcPath    *string = flag.String("srcPath", "", "Path to the source root.")
+	srcPath    *string = flag.String("srcPath", "", "Path to the source root."){{if .Pprof}}
+	pprofAddr  *string = flag.String("pprofAddr", "", "The address of the net/http/pprof server."){{end}}
 
 )
 
 func main() {
-	flag.Parse()
+	flag.Parse(){{if .Pprof}}
+	if *pprofAddr != "" {
+		go func() {
+			log.Println("The pprof server stopped:", http.ListenAndServe(*pprofAddr, nil))
+		}()
+	}{{end}}
 	revel.Init(*runMode, *importPath, *srcPath)
 	run.Run(*port)
 }
@@ -0,0 +1,69 @@
+package harness
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the coverage is measured for the packages of the application and the generated main.
+func TestCoverPackages(t *testing.T) {
+	basePath, err := ioutil.TempDir("", "revel-cover")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(basePath)
+	files := map[string]string{
+		"go.mod":                         "module example.com/cover\n\ngo 1.13\n",
+		"app/init.go":                    "package app\n",
+		"app/controllers/app.go":         "package controllers\n",
+		"app/models/user.go":             "package models\n",
+		"app/routes/routes.go":           "package routes\n",
+		"app/tmp/main.go":                "package main\n",
+		"app/tmp/run/run.go":             "package run\n",
+		"tests/apptest.go":               "package tests\n",
+		"tests/fixtures/fixtures.go":     "package fixtures\n",
+		"vendor/example.com/dep/dep.go":  "package dep\n",
+		"vendor/example.com/dep/util.go": "package dep\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(basePath, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	c := &model.CommandConfig{GoCmd: "go", AppPath: basePath, Vendored: true}
+	paths := &model.RevelContainer{ImportPath: "example.com/cover", BasePath: basePath}
+	packages := strings.Split(coverPackages(c, paths), ",")
+	assert.ElementsMatch(t, []string{
+		"example.com/cover/app",
+		"example.com/cover/app/controllers",
+		"example.com/cover/app/models",
+		"example.com/cover/app/tmp",
+	}, packages)
+
+	// Every package is measured when none of the application is listed
+	paths.ImportPath = "example.com/missing"
+	assert.Equal(t, "example.com/missing/...", coverPackages(c, paths))
+}
+
+func TestInstrumentationFlags(t *testing.T) {
+	paths := &model.RevelContainer{ImportPath: "example.com/web"}
+	c := &model.CommandConfig{Index: model.RUN}
+	assert.Empty(t, instrumentationFlags(c, paths))
+
+	// The debugger is only used by revel run
+	c.Run.Delve = true
+	assert.Equal(t, []string{"-gcflags=all=-N -l"}, instrumentationFlags(c, paths))
+	c.Run.Race = true
+	assert.Equal(t, []string{"-gcflags=all=-N -l", "-race"}, instrumentationFlags(c, paths))
+	c.Index = model.TEST
+	assert.Empty(t, instrumentationFlags(c, paths))
+	c.Test.Race = true
+	assert.Equal(t, []string{"-race"}, instrumentationFlags(c, paths))
+}
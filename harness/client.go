@@ -0,0 +1,277 @@
+package harness
+
+import (
+	"go/format"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+type (
+	// The controller passed to the client template.
+	clientController struct {
+		Name    string
+		Actions []*clientAction
+	}
+
+	// The action passed to the client template, one per controller method that has a route.
+	clientAction struct {
+		Name   string
+		Method string // The http method
+		Path   string // The path from the routes file, e.g. "/users/:id"
+		Args   []*clientArg
+	}
+
+	// The argument passed to the client template.
+	clientArg struct {
+		Name    string
+		Param   string // The name of the function parameter, prefixed so it can't shadow a package
+		Type    string // The type, qualified with the import alias
+		In      string // One of "path", "query" or "body"
+		Segment string // The path segment replaced by a path argument, e.g. ":id"
+		Escape  bool   // True if the path argument is escaped, wildcard ("*") segments are not
+		Kind    string // One of "value", "pointer", "slice" or "map" for query arguments
+	}
+)
+
+// The packages imported by the client template.
+var clientImports = []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "strings"}
+
+// GenerateClient writes a Go client package for the application to the target file, with one method per
+// routed controller action. Actions without a route are skipped, since no url can be created for them.
+func GenerateClient(paths *model.RevelContainer, sourceInfo *model.SourceInfo, targetFile, packageName string) (err error) {
+	routes, err := paths.LoadRoutes()
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to load routes")
+	}
+
+	// The imports of the template are added first, so the argument packages can't take their names
+	aliases := map[string]string{}
+	for _, importPath := range clientImports {
+		aliases[importPath] = path.Base(importPath)
+	}
+	controllers := []*clientController{}
+	for _, spec := range sourceInfo.ControllerSpecs() {
+		controller := &clientController{Name: spec.StructName}
+		for _, method := range spec.MethodSpecs {
+			route := findClientRoute(routes, spec.StructName, method.Name)
+			if route == nil {
+				utils.Logger.Info("No route found for action, skipping", "action", spec.StructName+"."+method.Name)
+				continue
+			}
+			addArgAliases(aliases, method)
+			action, err := newClientAction(route, method, aliases)
+			if err != nil {
+				return err
+			}
+			controller.Actions = append(controller.Actions, action)
+		}
+		if len(controller.Actions) > 0 {
+			controllers = append(controllers, controller)
+		}
+	}
+	for _, importPath := range clientImports {
+		delete(aliases, importPath)
+	}
+
+	if err = utils.GenerateTemplate(targetFile, RevelClientTemplate, map[string]interface{}{
+		"AppName":     paths.AppName,
+		"PackageName": packageName,
+		"Controllers": controllers,
+		"ImportPaths": aliases,
+	}); err != nil {
+		return
+	}
+
+	// Format the result, so the package reads like any other
+	source, err := ioutil.ReadFile(targetFile)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to read client", "path", targetFile)
+	}
+	formatted, err := format.Source(source)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to format client", "path", targetFile)
+	}
+	if err = ioutil.WriteFile(targetFile, formatted, 0644); err != nil {
+		return utils.NewBuildIfError(err, "Failed to write client", "path", targetFile)
+	}
+	return
+}
+
+// Returns the first route for the action, like the reverse router. A variable route
+// (e.g. "/:controller/:action") is returned with the controller and action filled in.
+func findClientRoute(routes []*model.Route, controllerName, methodName string) *model.Route {
+	for _, route := range routes {
+		if route.Method == "WS" {
+			continue
+		}
+		if !route.IsVariable() {
+			if strings.EqualFold(route.ControllerName(), controllerName) && strings.EqualFold(route.MethodName(), methodName) {
+				return route
+			}
+			continue
+		}
+
+		// Only the ":controller.:action" form can be used to reach any action
+		if route.ControllerName() != ":controller" || route.MethodName() != ":action" {
+			continue
+		}
+		variable := *route
+		variable.Path = strings.Replace(variable.Path, ":controller", strings.ToLower(controllerName), 1)
+		variable.Path = strings.Replace(variable.Path, ":action", strings.ToLower(methodName), 1)
+		variable.Action = controllerName + "." + methodName
+		return &variable
+	}
+	return nil
+}
+
+// Creates the template action for the route and method. Only one argument can be sent as the
+// json body, an error is returned for an action with more.
+func newClientAction(route *model.Route, method *model.MethodSpec, aliases map[string]string) (*clientAction, error) {
+	action := &clientAction{Name: method.Name, Method: route.Method, Path: route.Path}
+	if action.Method == "*" {
+		action.Method = "GET"
+	}
+
+	pathParams := route.PathParams()
+	for i, methodArg := range method.Args {
+		// Fixed arguments are supplied by the routes file
+		if i < len(route.FixedArgs) {
+			continue
+		}
+		arg := &clientArg{
+			Name:  methodArg.Name,
+			Param: "p_" + methodArg.Name,
+			Type:  methodArg.TypeExpr.TypeName(aliases[methodArg.ImportPath]),
+			In:    "query",
+			Kind:  "value",
+		}
+		expr := methodArg.TypeExpr.Expr
+		switch {
+		case utils.ContainsString(pathParams, arg.Name):
+			arg.In = "path"
+			arg.Segment, arg.Escape = ":"+arg.Name, true
+			if !strings.Contains(route.Path, arg.Segment) {
+				arg.Segment, arg.Escape = "*"+arg.Name, false
+			}
+		case methodArg.ImportPath != "" || !model.IsBuiltinType(strings.TrimLeft(expr, "*[]")):
+			// Structs are sent as json, which the framework binds to the argument
+			if strings.HasPrefix(expr, "map[") {
+				arg.Kind = "map"
+			} else {
+				arg.In = "body"
+			}
+		case strings.HasPrefix(expr, "[]"):
+			arg.Kind = "slice"
+		case strings.HasPrefix(expr, "*"):
+			arg.Kind = "pointer"
+		}
+		if arg.In == "body" {
+			for _, other := range action.Args {
+				if other.In == "body" {
+					return nil, utils.NewBuildError("Action has more than one argument sent as the body",
+						"action", route.Action, "args", other.Name+", "+arg.Name)
+				}
+			}
+		}
+		action.Args = append(action.Args, arg)
+	}
+	return action, nil
+}
+
+// RevelClientTemplate template for the client package generated by "revel generate client".
+const RevelClientTemplate = `// GENERATED CODE - DO NOT EDIT
+// This file provides a client for calling the actions of the {{.AppName}} application,
+// the urls are created from the routes file.
+package {{.PackageName}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"{{range $k, $v := $.ImportPaths}}
+	{{$v}} "{{$k}}"{{end}}
+)
+
+var (
+	// So compiler won't complain if the generated code doesn't reference fmt.
+	_ = fmt.Sprint
+)
+
+// Client calls the actions of the application.
+type Client struct {
+	BaseURL    string       // The url of the application, e.g. http://localhost:9000
+	HTTPClient *http.Client // The client used to send the requests
+{{range .Controllers}}
+	{{.Name}} t{{.Name}}{{end}}
+}
+
+// NewClient returns a client for the application served from the base url.
+func NewClient(baseURL string) *Client {
+	c := &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient}{{range .Controllers}}
+	c.{{.Name}} = t{{.Name}}{client: c}{{end}}
+	return c
+}
+
+// Do sends a request to the path. The values are sent as a form for POST, PUT and PATCH
+// requests without a body, otherwise they are added to the query. The body is sent as json.
+func (c *Client) Do(ctx context.Context, method, path string, values url.Values, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	contentType := ""
+	switch {
+	case body != nil:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader, contentType = bytes.NewReader(data), "application/json"
+	case method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch:
+		reader, contentType = strings.NewReader(values.Encode()), "application/x-www-form-urlencoded"
+		values = nil
+	}
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.HTTPClient.Do(req)
+}
+{{range $c := .Controllers}}
+type t{{.Name}} struct {
+	client *Client
+}
+{{range .Actions}}
+// {{.Name}} calls {{.Method}} {{.Path}}
+func (_t t{{$c.Name}}) {{.Name}}(ctx context.Context{{range .Args}}, {{.Param}} {{.Type}}{{end}}) (*http.Response, error) {
+	_path := "{{.Path}}"
+	_values := url.Values{}
+	var _body interface{}{{range .Args}}{{if eq .In "path"}}
+	_path = strings.Replace(_path, "{{.Segment}}", {{if .Escape}}url.PathEscape(fmt.Sprint({{.Param}})){{else}}fmt.Sprint({{.Param}}){{end}}, 1){{else if eq .In "body"}}
+	_body = {{.Param}}{{else if eq .Kind "slice"}}
+	for _, v := range {{.Param}} {
+		_values.Add("{{.Name}}", fmt.Sprint(v))
+	}{{else if eq .Kind "map"}}
+	for k, v := range {{.Param}} {
+		_values.Set(fmt.Sprintf("{{.Name}}[%v]", k), fmt.Sprint(v))
+	}{{else if eq .Kind "pointer"}}
+	if {{.Param}} != nil {
+		_values.Set("{{.Name}}", fmt.Sprint(*{{.Param}}))
+	}{{else}}
+	_values.Set("{{.Name}}", fmt.Sprint({{.Param}})){{end}}{{end}}
+	return _t.client.Do(ctx, "{{.Method}}", _path, _values, _body)
+}
+{{end}}{{end}}
+`
@@ -0,0 +1,74 @@
+package harness_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Adds a routed action with arguments named after the packages the client imports.
+func addClientSearch(t *testing.T, paths *model.RevelContainer, sourceInfo *model.SourceInfo, args ...*model.MethodArg) {
+	routes, err := os.OpenFile(filepath.Join(paths.BasePath, "conf", "routes"), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	_, err = routes.WriteString("POST    /search/:url        Users.Search\n")
+	assert.Nil(t, err)
+	assert.Nil(t, routes.Close())
+
+	controller := sourceInfo.StructSpecs[0]
+	controller.MethodSpecs = append(controller.MethodSpecs, &model.MethodSpec{Name: "Search", Args: args})
+}
+
+func TestGenerateClient(t *testing.T) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	paths, sourceInfo := newOpenAPIApp(t)
+	addClientSearch(t, paths, sourceInfo,
+		&model.MethodArg{Name: "url", TypeExpr: model.NewTypeExprFromData("string", "", 0, true)},
+		&model.MethodArg{Name: "ctx", TypeExpr: model.NewTypeExprFromData("string", "", 0, true)},
+		&model.MethodArg{Name: "fmt", TypeExpr: model.NewTypeExprFromData("[]string", "", 2, true)},
+		&model.MethodArg{Name: "strings", TypeExpr: model.NewTypeExprFromData("map[string]int", "", 0, true)},
+		&model.MethodArg{Name: "json", TypeExpr: model.NewTypeExprFromData("*int", "", 1, true)},
+	)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(paths.BasePath, "go.mod"), []byte("module example.com/app\n\ngo 1.17\n"), 0644))
+
+	target := filepath.Join(paths.BasePath, "client", "client.go")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(target), 0755))
+	assert.Nil(t, harness.GenerateClient(paths, sourceInfo, target, "client"))
+
+	source, err := ioutil.ReadFile(target)
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), "func (_t tUsers) Search(ctx context.Context, p_url string, p_ctx string, p_fmt []string, p_strings map[string]int, p_json *int)")
+	assert.Contains(t, string(source), `_values.Set("ctx", fmt.Sprint(p_ctx))`)
+	assert.Contains(t, string(source), `func (_t tUsers) Create(ctx context.Context, p_user *models.User)`)
+
+	build := exec.Command(goCmd, "build", "./...")
+	build.Dir = paths.BasePath
+	build.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	output, err := build.CombinedOutput()
+	assert.Nil(t, err, "The generated client does not compile: %s\n%s", output, source)
+}
+
+func TestGenerateClientBodies(t *testing.T) {
+	paths, sourceInfo := newOpenAPIApp(t)
+	user := model.NewTypeExprFromData("*User", "models", 1, true)
+	addClientSearch(t, paths, sourceInfo,
+		&model.MethodArg{Name: "url", TypeExpr: model.NewTypeExprFromData("string", "", 0, true)},
+		&model.MethodArg{Name: "first", TypeExpr: user, ImportPath: "example.com/app/app/models"},
+		&model.MethodArg{Name: "second", TypeExpr: user, ImportPath: "example.com/app/app/models"},
+	)
+
+	target := filepath.Join(paths.BasePath, "client.go")
+	err := harness.GenerateClient(paths, sourceInfo, target, "client")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "more than one argument"), err.Error())
+	}
+}
@@ -0,0 +1,237 @@
+package harness
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/revel/cmd/utils"
+)
+
+const (
+	devCAName       = "Revel development CA"
+	devCAValidity   = 10 * 365 * 24 * time.Hour
+	devCertValidity = 825 * 24 * time.Hour // The longest validity browsers accept
+	devCertRenewal  = 30 * 24 * time.Hour  // The certificate is renewed when it expires sooner
+)
+
+// DevCertificate is the certificate the proxy serves with revel run --https, signed by a local
+// CA. Both are kept in the user config folder, so the CA only needs to be trusted once.
+type DevCertificate struct {
+	Dir        string // The folder of the files
+	CAFile     string // The certificate of the CA, which is trusted by the browser
+	CAKeyFile  string
+	CertFile   string // The certificate of the proxy
+	KeyFile    string
+	CACreated  bool // True if the CA was created now, so it is not trusted yet
+	CertIssued bool // True if the certificate was issued now
+}
+
+// LoadDevCertificate returns the development certificate for the hosts, localhost is always
+// included. The CA and the certificate are created when missing, and the certificate is issued
+// again when it is about to expire or does not cover the hosts.
+func LoadDevCertificate(hosts ...string) (cert *DevCertificate, err error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to find the user config folder for the certificates")
+	}
+	dir := filepath.Join(configDir, "revel", "devcert")
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to create the certificate folder", "path", dir)
+	}
+	cert = &DevCertificate{
+		Dir:       dir,
+		CAFile:    filepath.Join(dir, "ca.pem"),
+		CAKeyFile: filepath.Join(dir, "ca-key.pem"),
+		CertFile:  filepath.Join(dir, "localhost.pem"),
+		KeyFile:   filepath.Join(dir, "localhost-key.pem"),
+	}
+
+	ca, caKey, err := loadCertificate(cert.CAFile, cert.CAKeyFile)
+	if err != nil || time.Now().Add(devCertRenewal).After(ca.NotAfter) {
+		utils.Logger.Info("Creating the development CA", "path", cert.CAFile)
+		if ca, caKey, err = createCertificate(cert.CAFile, cert.CAKeyFile, nil, nil, nil); err != nil {
+			return nil, utils.NewBuildIfError(err, "Failed to create the development CA", "path", cert.CAFile)
+		}
+		cert.CACreated = true
+	}
+
+	hosts = append([]string{"localhost", "127.0.0.1", "::1"}, hosts...)
+	if current, _, err := loadCertificate(cert.CertFile, cert.KeyFile); err == nil && !cert.CACreated &&
+		time.Now().Add(devCertRenewal).Before(current.NotAfter) && current.CheckSignatureFrom(ca) == nil && covers(current, hosts) {
+		return cert, nil
+	}
+	utils.Logger.Info("Issuing the development certificate", "path", cert.CertFile, "hosts", hosts)
+	if _, _, err = createCertificate(cert.CertFile, cert.KeyFile, hosts, ca, caKey); err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to issue the development certificate", "path", cert.CertFile)
+	}
+	cert.CertIssued = true
+	return cert, nil
+}
+
+// TrustInstructions returns the commands which add the CA to the trust store of the system.
+func (cert *DevCertificate) TrustInstructions() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return fmt.Sprintf("    sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s\n", cert.CAFile)
+	case "windows":
+		return fmt.Sprintf("    certutil -addstore -f ROOT %s\n", cert.CAFile)
+	}
+	return fmt.Sprintf(`    sudo cp %s /usr/local/share/ca-certificates/revel-development-ca.crt
+    sudo update-ca-certificates
+
+Firefox and Chrome keep their own store, add the CA to it with certutil (libnss3-tools):
+
+    certutil -d sql:$HOME/.pki/nssdb -A -t C,, -n %q -i %s
+`, cert.CAFile, devCAName, cert.CAFile)
+}
+
+// Returns true if the certificate is valid for all of the hosts.
+func covers(cert *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Reads the certificate and its key from the PEM files.
+func loadCertificate(certFile, keyFile string) (cert *x509.Certificate, key crypto.Signer, err error) {
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return
+	}
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data in %s or %s", certFile, keyFile)
+	}
+	if cert, err = x509.ParseCertificate(certBlock.Bytes); err != nil {
+		return
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return
+	}
+	key, ok := parsedKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported key in %s", keyFile)
+	}
+	return
+}
+
+// Creates a certificate for the hosts signed by the parent and writes it with its key. A self
+// signed CA is created when the parent is nil.
+func createCertificate(certFile, keyFile string, hosts []string, parent *x509.Certificate, parentKey crypto.Signer) (
+	cert *x509.Certificate, key crypto.Signer, err error) {
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{devCAName}, OrganizationalUnit: []string{hostname}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(devCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if parent == nil {
+		template.Subject.CommonName = devCAName
+		template.NotAfter = time.Now().Add(devCAValidity)
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		template.ExtKeyUsage = nil
+		template.BasicConstraintsValid = true
+		template.IsCA = true
+		template.MaxPathLenZero = true
+		parent, parentKey = template, key
+	} else {
+		template.Subject.CommonName = hosts[0]
+		for _, host := range hosts {
+			if ip := net.ParseIP(host); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			} else {
+				template.DNSNames = append(template.DNSNames, host)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		return
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return
+	}
+	err = writePEMFiles(
+		&pemFile{keyFile, &pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}, 0600},
+		&pemFile{certFile, &pem.Block{Type: "CERTIFICATE", Bytes: der}, 0644},
+	)
+	return
+}
+
+// A PEM file written by writePEMFiles.
+type pemFile struct {
+	path  string
+	block *pem.Block
+	perm  os.FileMode
+}
+
+// Writes the files to temp files in their folder, which are renamed once all of them are
+// written, so a failure does not leave a certificate next to the key of another one.
+func writePEMFiles(files ...*pemFile) (err error) {
+	temps := make([]string, 0, len(files))
+	defer func() {
+		for _, temp := range temps {
+			_ = os.Remove(temp)
+		}
+	}()
+	for _, file := range files {
+		temp, err := ioutil.TempFile(filepath.Dir(file.path), filepath.Base(file.path)+".*.tmp")
+		if err != nil {
+			return err
+		}
+		temps = append(temps, temp.Name())
+		if err = temp.Chmod(file.perm); err == nil {
+			err = pem.Encode(temp, file.block)
+		}
+		if closeErr := temp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for i, file := range files {
+		if err = os.Rename(temps[i], file.path); err != nil {
+			return
+		}
+	}
+	return
+}
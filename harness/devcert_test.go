@@ -0,0 +1,126 @@
+package harness
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Points the user config folder at a temp folder, so the certificates of the user are not touched.
+func setupDevCertDir(t *testing.T) string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("The user config folder is only read from XDG_CONFIG_HOME on unix")
+	}
+	dir, err := ioutil.TempDir("", "revel-devcert")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	return filepath.Join(dir, "revel", "devcert")
+}
+
+// Verifies the certificate of the files is signed by the CA and valid for the hosts.
+func verifyDevCertificate(t *testing.T, cert *DevCertificate, hosts ...string) {
+	ca, _, err := loadCertificate(cert.CAFile, cert.CAKeyFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	issued, _, err := loadCertificate(cert.CertFile, cert.KeyFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, ca.IsCA)
+	assert.Nil(t, issued.CheckSignatureFrom(ca))
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, host := range hosts {
+		_, err = issued.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.Nil(t, err, host)
+	}
+}
+
+func readFiles(t *testing.T, paths ...string) (contents []string) {
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		contents = append(contents, string(content))
+	}
+	return
+}
+
+func TestLoadDevCertificate(t *testing.T) {
+	dir := setupDevCertDir(t)
+
+	cert, err := LoadDevCertificate()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, dir, cert.Dir)
+	assert.True(t, cert.CACreated)
+	assert.True(t, cert.CertIssued)
+	verifyDevCertificate(t, cert, "localhost", "127.0.0.1", "::1")
+	for _, keyFile := range []string{cert.CAKeyFile, cert.KeyFile} {
+		info, err := os.Stat(keyFile)
+		if assert.Nil(t, err) {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), keyFile)
+		}
+	}
+	temps, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	assert.Empty(t, temps)
+
+	// The certificate is reused while it covers the hosts
+	files := readFiles(t, cert.CAFile, cert.CAKeyFile, cert.CertFile, cert.KeyFile)
+	cert, err = LoadDevCertificate("127.0.0.1")
+	assert.Nil(t, err)
+	assert.False(t, cert.CACreated)
+	assert.False(t, cert.CertIssued)
+	assert.Equal(t, files, readFiles(t, cert.CAFile, cert.CAKeyFile, cert.CertFile, cert.KeyFile))
+
+	// A new host issues the certificate again with the same CA
+	cert, err = LoadDevCertificate("myapp.test")
+	assert.Nil(t, err)
+	assert.False(t, cert.CACreated)
+	assert.True(t, cert.CertIssued)
+	verifyDevCertificate(t, cert, "localhost", "myapp.test")
+	assert.Equal(t, files[:2], readFiles(t, cert.CAFile, cert.CAKeyFile))
+}
+
+func TestLoadDevCertificateSignature(t *testing.T) {
+	setupDevCertDir(t)
+	cert, err := LoadDevCertificate()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// A certificate which is not signed by the CA is issued again
+	_, _, err = createCertificate(cert.CAFile, cert.CAKeyFile, nil, nil, nil)
+	assert.Nil(t, err)
+	current, _, _ := loadCertificate(cert.CertFile, cert.KeyFile)
+	ca, _, _ := loadCertificate(cert.CAFile, cert.CAKeyFile)
+	assert.NotNil(t, current.CheckSignatureFrom(ca))
+	cert, err = LoadDevCertificate()
+	assert.Nil(t, err)
+	assert.False(t, cert.CACreated)
+	assert.True(t, cert.CertIssued)
+	verifyDevCertificate(t, cert, "localhost")
+
+	// A missing CA is created, and the certificate issued by it
+	assert.Nil(t, os.Remove(cert.CAKeyFile))
+	cert, err = LoadDevCertificate()
+	assert.Nil(t, err)
+	assert.True(t, cert.CACreated)
+	assert.True(t, cert.CertIssued)
+	verifyDevCertificate(t, cert, "localhost")
+
+	// A broken certificate is issued again
+	assert.Nil(t, ioutil.WriteFile(cert.CertFile, []byte("broken"), 0644))
+	cert, err = LoadDevCertificate()
+	assert.Nil(t, err)
+	assert.False(t, cert.CACreated)
+	assert.True(t, cert.CertIssued)
+	verifyDevCertificate(t, cert, "localhost")
+}
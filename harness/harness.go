@@ -14,6 +14,7 @@
 package harness
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -37,14 +38,19 @@ import (
 	"github.com/revel/cmd/model"
 	"github.com/revel/cmd/utils"
 	"github.com/revel/cmd/watcher"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
+// PprofPath is the path of the proxy which is forwarded to the net/http/pprof endpoints of the
+// application, when it is run with --pprof.
+const PprofPath = "/@harness/pprof/"
+
 var (
 	doNotWatch = []string{"tmp", "views", "routes"}
 
-	lastRequestHadError int32
-	startupError        int32
-	startupErrorText    error
+	startupError     int32
+	startupErrorText error
 )
 
 // Harness reverse proxies requests to the application server.
@@ -56,11 +62,26 @@ type Harness struct {
 	port       int                    // The proxy serber port
 	proxy      *httputil.ReverseProxy // The proxy
 	watcher    *watcher.Watcher       // The file watched
+	templates  *templateListener      // The check of the views, nil if they are not watched
 	mutex      *sync.Mutex            // A mutex to prevent concurrent updates
 	paths      *model.RevelContainer  // The Revel container
 	config     *model.CommandConfig   // The configuration
 	runMode    string                 // The runmode the harness is running in
 	ranOnce    bool                   // True app compiled once
+	name       string                 // The application name, shown when several applications are run
+	pprofAddr  string                 // The address of the pprof server of the application
+	pprofProxy *httputil.ReverseProxy // The proxy to the pprof server
+	inspector  *Inspector             // The recorder of the recent requests, if enabled
+
+	rebuildPage   bool          // True if browsers are shown a page while the app is rebuilt
+	rebuildHold   time.Duration // How long requests wait for a rebuild before the page is shown
+	rebuilding    int32         // True while the app is rebuilt
+	notifyMutex   sync.Mutex
+	pendingNotify chan *utils.SourceError // The result of the rebuild a page was shown for
+	crashMutex    sync.Mutex
+	crash         *utils.SourceError // The error of the app when it exited on its own, shown on the next request
+
+	lastRequestHadError int32 // True if the last request rendered a build error
 }
 
 func (h *Harness) renderError(iw http.ResponseWriter, ir *http.Request, err error) {
@@ -117,34 +138,83 @@ func (h *Harness) renderError(iw http.ResponseWriter, ir *http.Request, err erro
 	viewArgs["Error"] = revelError
 
 	// Render the template from the file
-	err = templateSet.ExecuteTemplate(iw, "errors/500.html", viewArgs)
-	if err != nil {
+	if revelError.Panic == nil || !h.paths.DevMode {
+		err = templateSet.ExecuteTemplate(iw, "errors/500.html", viewArgs)
+		if err != nil {
+			utils.Logger.Error("Failed to execute", "error", err)
+		}
+		return
+	}
+
+	// The goroutines of a panic are added to the page
+	page := &bytes.Buffer{}
+	if err = templateSet.ExecuteTemplate(page, "errors/500.html", viewArgs); err != nil {
 		utils.Logger.Error("Failed to execute", "error", err)
 	}
+	if _, err = iw.Write(addPanicFrames(page.Bytes(), revelError.Panic)); err != nil {
+		utils.Logger.Error("Failed to write the error page", "error", err)
+	}
 }
 
 // ServeHTTP handles all requests.
 // It checks for changes to app, rebuilds if necessary, and forwards the request.
 func (h *Harness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Don't rebuild the app for favicon requests.
-	if lastRequestHadError > 0 && r.URL.Path == "/favicon.ico" {
+	if atomic.LoadInt32(&h.lastRequestHadError) > 0 && r.URL.Path == "/favicon.ico" {
+		return
+	}
+
+	// The profiles are taken from the running app, without rebuilding it
+	if strings.HasPrefix(r.URL.Path, PprofPath) {
+		h.servePprof(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, InspectorPath) {
+		if h.inspector == nil {
+			http.Error(w, "The requests are recorded when the application is run with revel run --inspect", http.StatusNotFound)
+			return
+		}
+		h.inspector.ServeHTTP(w, r, http.HandlerFunc(h.serveApp))
+		return
+	}
+	if h.inspector != nil && !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		var done func()
+		w, done = h.inspector.Record(w, r)
+		defer done()
+	}
+	h.serveApp(w, r)
+}
+
+// Rebuilds the app if necessary and forwards the request to it.
+func (h *Harness) serveApp(w http.ResponseWriter, r *http.Request) {
+	// The app exited since the last request, show why, the next request rebuilds it
+	if crash := h.takeCrash(); crash != nil {
+		atomic.CompareAndSwapInt32(&h.lastRequestHadError, 0, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		h.renderError(w, r, crash)
 		return
 	}
 
 	// Flush any change events and rebuild app if necessary.
 	// Render an error page if the rebuild / restart failed.
-	err := h.watcher.Notify()
+	err, served := h.notify(w, r)
+	if served {
+		return
+	}
+	if err == nil {
+		err = h.templates.Err()
+	}
 	if err != nil {
 		// In a thread safe manner update the flag so that a request for
 		// /favicon.ico does not trigger a rebuild
-		atomic.CompareAndSwapInt32(&lastRequestHadError, 0, 1)
+		atomic.CompareAndSwapInt32(&h.lastRequestHadError, 0, 1)
 		h.renderError(w, r, err)
 		return
 	}
 
 	// In a thread safe manner update the flag so that a request for
 	// /favicon.ico is allowed
-	atomic.CompareAndSwapInt32(&lastRequestHadError, 1, 0)
+	atomic.CompareAndSwapInt32(&h.lastRequestHadError, 1, 0)
 
 	// Reverse proxy the request.
 	// (Need special code for websockets, courtesy of bradfitz)
@@ -155,6 +225,112 @@ func (h *Harness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Flushes the change events and rebuilds the app if necessary. A browser is shown the rebuilding
+// page instead of waiting longer than the hold time for the rebuild, which continues in the
+// background. Served is true if the page was shown.
+func (h *Harness) notify(w http.ResponseWriter, r *http.Request) (err *utils.SourceError, served bool) {
+	if !h.rebuildPage || (r.Method != http.MethodGet && r.Method != http.MethodHead) ||
+		!strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return h.watcher.Notify(), false
+	}
+
+	// A rebuild left by the previous page is awaited, so its error is shown instead of rebuilding again
+	h.notifyMutex.Lock()
+	result := h.pendingNotify
+	h.pendingNotify = nil
+	h.notifyMutex.Unlock()
+	if result == nil {
+		result = make(chan *utils.SourceError, 1)
+		go func() {
+			result <- h.watcher.Notify()
+		}()
+	}
+	deadline := time.Now().Add(h.rebuildHold)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err = <-result:
+			return err, false
+		case <-ticker.C:
+			if atomic.LoadInt32(&h.rebuilding) == 1 && time.Now().After(deadline) {
+				h.notifyMutex.Lock()
+				if h.pendingNotify == nil {
+					h.pendingNotify = result
+				}
+				h.notifyMutex.Unlock()
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Header().Set("Cache-Control", "no-store")
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				if err := rebuildingTemplate.Execute(w, h.paths.AppName); err != nil {
+					utils.Logger.Error("Failed to render the rebuilding page", "error", err)
+				}
+				return nil, true
+			}
+		}
+	}
+}
+
+// Renders the error of the proxy, instead of an empty response, when the app does not answer.
+func (h *Harness) proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	utils.Logger.Error("Proxy error", "path", r.URL.Path, "error", err)
+	// The request may have crashed the app, give the harness a moment to notice the exit
+	crash := h.takeCrash()
+	for start := time.Now(); crash == nil && r.Context().Err() == nil && time.Since(start) < time.Second; crash = h.takeCrash() {
+		time.Sleep(50 * time.Millisecond)
+	}
+	w.WriteHeader(http.StatusBadGateway)
+	if crash != nil {
+		h.renderError(w, r, crash)
+		return
+	}
+	h.renderError(w, r, &utils.SourceError{
+		Title:       "Proxy Error",
+		Description: "The application did not answer the request: " + err.Error(),
+	})
+}
+
+// Waits for the app to exit. If it exited on its own the error is shown on the next request and
+// the app is rebuilt on the one after.
+func (h *Harness) watchExit(app *App) {
+	<-app.Exited()
+	crash := app.ExitError()
+	if crash == nil {
+		return
+	}
+	fmt.Println("\nThe application exited, it is rebuilt on the next request")
+	panicError(crash, h.paths)
+	h.setCrash(crash)
+	h.watcher.ForceRefresh()
+}
+
+// Returns the error of the app which exited and clears it.
+func (h *Harness) takeCrash() (crash *utils.SourceError) {
+	h.crashMutex.Lock()
+	defer h.crashMutex.Unlock()
+	crash, h.crash = h.crash, nil
+	return
+}
+
+func (h *Harness) setCrash(crash *utils.SourceError) {
+	h.crashMutex.Lock()
+	defer h.crashMutex.Unlock()
+	h.crash = crash
+}
+
+// Forwards the request to the net/http/pprof endpoints of the application.
+func (h *Harness) servePprof(w http.ResponseWriter, r *http.Request) {
+	if h.pprofProxy == nil {
+		http.Error(w, "The profiles are served when the application is run with revel run --pprof", http.StatusNotFound)
+		return
+	}
+	r = r.Clone(r.Context())
+	r.URL.Path = "/debug/pprof/" + strings.TrimPrefix(r.URL.Path, PprofPath)
+	r.URL.RawPath = ""
+	h.pprofProxy.ServeHTTP(w, r)
+}
+
 // NewHarness method returns a reverse proxy that forwards requests
 // to the given port.
 func NewHarness(c *model.CommandConfig, paths *model.RevelContainer, runMode string, noProxy bool) *Harness {
@@ -198,10 +374,22 @@ func NewHarness(c *model.CommandConfig, paths *model.RevelContainer, runMode str
 		runMode:    runMode,
 	}
 
-	if paths.HTTPSsl {
-		serverHarness.proxy.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	if transport := backendTransport(paths); transport != nil {
+		serverHarness.proxy.Transport = transport
+	}
+	serverHarness.proxy.ErrorHandler = serverHarness.proxyError
+	serverHarness.rebuildPage = paths.Config.BoolDefault("harness.rebuild.page", true)
+	serverHarness.rebuildHold = time.Duration(paths.Config.IntDefault("harness.rebuild.hold", 0)) * time.Second
+
+	if c.Run.Inspect {
+		serverHarness.inspector = NewInspector(paths.Config.IntDefault("harness.inspect.size", 100),
+			paths.Config.IntDefault("harness.inspect.body", 64*1024))
+	}
+
+	// The pprof server runs on a side port of the application
+	if c.Run.Pprof {
+		serverHarness.pprofAddr = fmt.Sprintf("localhost:%d", getFreePort())
+		serverHarness.pprofProxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: serverHarness.pprofAddr})
 	}
 	return serverHarness
 }
@@ -210,8 +398,15 @@ func NewHarness(c *model.CommandConfig, paths *model.RevelContainer, runMode str
 // called by the watcher.
 func (h *Harness) Refresh() (err *utils.SourceError) {
 	t := time.Now()
-	fmt.Println("Change detected, recompiling")
+	if h.name != "" {
+		fmt.Printf("Change detected in %s, recompiling\n", h.name)
+	} else {
+		fmt.Println("Change detected, recompiling")
+	}
 	err = h.refresh()
+	if err != nil {
+		fmt.Println(err.TerminalString())
+	}
 	if err != nil && !h.ranOnce && h.useProxy {
 		addr := fmt.Sprintf("%s:%d", h.paths.HTTPAddr, h.paths.HTTPPort)
 
@@ -231,16 +426,20 @@ func (h *Harness) refresh() (err *utils.SourceError) {
 	// Once no more requests are triggered the build will be processed
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
+	atomic.StoreInt32(&h.rebuilding, 1)
+	defer atomic.StoreInt32(&h.rebuilding, 0)
 
 	if h.app != nil {
 		h.app.Kill()
 	}
+	h.setCrash(nil)
 
 	utils.Logger.Info("Rebuild Called")
 	var newErr error
 	h.app, newErr = Build(h.config, h.paths)
 	if newErr != nil {
-		utils.Logger.Error("Build detected an error", "error", newErr)
+		// The error is printed with its source by Refresh
+		utils.Logger.Error("Build detected an error")
 
 		var castErr *utils.SourceError
 		if errors.As(newErr, &castErr) {
@@ -257,7 +456,24 @@ func (h *Harness) refresh() (err *utils.SourceError) {
 
 	if h.useProxy {
 		h.app.Port = h.port
+		h.app.PprofAddr = h.pprofAddr
 		runMode := h.runMode
+		if h.config.Run.Delve {
+			if err2 := h.app.Debug(h.config.Run.DelveAddr); err2 != nil {
+				return &utils.SourceError{
+					Title:       "App failed to start up",
+					Description: err2.Error(),
+				}
+			}
+		}
+		if h.config.Run.Cover {
+			if err2 := h.app.Cover(); err2 != nil {
+				return &utils.SourceError{
+					Title:       "App failed to start up",
+					Description: err2.Error(),
+				}
+			}
+		}
 
 		if !h.config.HistoricMode {
 			// Recalulate run mode based on the config
@@ -274,7 +490,8 @@ func (h *Harness) refresh() (err *utils.SourceError) {
 
 			var serr *utils.SourceError
 			if errors.As(err2, &serr) {
-				return err
+				panicError(serr, h.paths)
+				return serr
 			}
 
 			return &utils.SourceError{
@@ -282,6 +499,7 @@ func (h *Harness) refresh() (err *utils.SourceError) {
 				Description: err2.Error(),
 			}
 		}
+		go h.watchExit(h.app)
 	} else {
 		h.app = nil
 	}
@@ -304,53 +522,105 @@ func (h *Harness) WatchFile(filename string) bool {
 // Run the harness, which listens for requests and proxies them to the app
 // server, which it runs and rebuilds as necessary.
 func (h *Harness) Run() {
+	h.watch()
+	if h.useProxy {
+		go listen(h.paths, h)
+	}
+	waitForInterrupt(h)
+}
+
+// Starts watching the application code and builds the application.
+func (h *Harness) watch() {
 	var paths []string
 	if h.paths.Config.BoolDefault("watch.gopath", false) {
 		gopaths := filepath.SplitList(build.Default.GOPATH)
 		paths = append(paths, gopaths...)
 	}
 	paths = append(paths, h.paths.CodePaths...)
+	paths = append(paths, h.paths.ModuleWatchPaths()...)
 	h.watcher = watcher.NewWatcher(h.paths, false)
 	h.watcher.Listen(h, paths...)
+	// The views are not in the paths of the app code, they are checked on their own
+	if dirs := templateDirs(h.paths); len(dirs) > 0 {
+		h.templates = &templateListener{paths: h.paths}
+		h.watcher.Listen(h.templates, dirs...)
+	}
 
 	go func() {
 		if err := h.Refresh(); err != nil {
-			utils.Logger.Error("Failed to refresh", "error", err)
+			utils.Logger.Error("Failed to refresh", "title", err.Title)
 		}
 	}()
+}
 
-	if h.useProxy {
-		go func() {
-			// Check the port to start on a random port
-			if h.paths.HTTPPort == 0 {
-				h.paths.HTTPPort = getFreePort()
-			}
-			addr := fmt.Sprintf("%s:%d", h.paths.HTTPAddr, h.paths.HTTPPort)
-			utils.Logger.Infof("Proxy server is listening on %s", addr)
-			var err error
-			if h.paths.HTTPSsl {
-				err = http.ListenAndServeTLS(
-					addr,
-					h.paths.HTTPSslCert,
-					h.paths.HTTPSslKey,
-					h)
-			} else {
-				err = http.ListenAndServe(addr, h)
-			}
-			if err != nil {
-				utils.Logger.Error("Failed to start reverse proxy:", "error", err)
-			}
-		}()
+// Returns the transport of the proxy to the application, nil for the default one. When
+// harness.backend.http2 is set the proxy talks HTTP/2 to the application, over TLS when http.ssl
+// is set or else as h2c with prior knowledge, which the application server must accept.
+func backendTransport(paths *model.RevelContainer) http.RoundTripper {
+	http2Backend := paths.Config.BoolDefault("harness.backend.http2", false)
+	if paths.HTTPSsl {
+		return &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: http2Backend,
+		}
+	}
+	if http2Backend {
+		return &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		}
+	}
+	return nil
+}
+
+// Starts the proxy server on the address of the container.
+func listen(paths *model.RevelContainer, handler http.Handler) {
+	// Check the port to start on a random port
+	if paths.HTTPPort == 0 {
+		paths.HTTPPort = getFreePort()
 	}
+	addr := fmt.Sprintf("%s:%d", paths.HTTPAddr, paths.HTTPPort)
+	utils.Logger.Infof("Proxy server is listening on %s", addr)
+	server := &http.Server{Addr: addr, Handler: handler}
+	certFile, keyFile := paths.HTTPSslCert, paths.HTTPSslKey
+	if paths.ProxySslCert != "" {
+		certFile, keyFile = paths.ProxySslCert, paths.ProxySslKey
+	}
+	var err error
+	if paths.HTTPSsl || paths.ProxySslCert != "" {
+		// HTTP/2 is negotiated on TLS unless it is turned off
+		if !paths.Config.BoolDefault("harness.http2", true) {
+			server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		} else if err = http2.ConfigureServer(server, nil); err != nil {
+			utils.Logger.Error("Failed to configure HTTP/2 for the reverse proxy", "error", err)
+		}
+		err = server.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		// Cleartext HTTP/2 is accepted both with prior knowledge and as an upgrade
+		if paths.Config.BoolDefault("harness.h2c", false) {
+			server.Handler = h2c.NewHandler(handler, &http2.Server{})
+		}
+		err = server.ListenAndServe()
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to start reverse proxy:", "error", err)
+	}
+}
 
+// Waits for the interrupt, then kills the applications and exits.
+func waitForInterrupt(harnesses ...*Harness) {
 	// Make a new channel to listen for the interrupt event
 	ch := make(chan os.Signal)
 	//nolint:staticcheck // os.Kill ineffective on Unix, useful on Windows?
 	signal.Notify(ch, os.Interrupt, os.Kill)
 	<-ch
-	// Kill the app and exit
-	if h.app != nil {
-		h.app.Kill()
+	// Kill the apps and exit
+	for _, h := range harnesses {
+		if h.app != nil {
+			h.app.Kill()
+		}
 	}
 	os.Exit(1)
 }
@@ -424,3 +694,25 @@ func (h *Harness) proxyWebsocket(w http.ResponseWriter, r *http.Request, host st
 	go cp(nc, d)
 	<-errc
 }
+
+// The page shown to a browser while the app is rebuilt, it reloads until the app is ready.
+var rebuildingTemplate = template.Must(template.New("rebuilding").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="1">
+<title>Rebuilding {{.}}</title>
+<style>
+body { font-family: Helvetica, Arial, Sans; background: #EEEEEE; margin: 0; }
+div { padding: 20px; background: #fcefd2; border-bottom: 1px solid #aaa; }
+h1 { font-weight: normal; font-size: 28px; margin: 0; }
+</style>
+</head>
+<body>
+<div>
+<h1>Rebuilding {{.}}&hellip;</h1>
+<p>The page reloads when the application is ready.</p>
+</div>
+</body>
+</html>
+`))
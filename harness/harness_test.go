@@ -0,0 +1,68 @@
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/watcher"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a harness for the application which proxies to a test server running the handler,
+// nothing is watched so the app is never rebuilt.
+func newTestHarness(t *testing.T, appName string, handler http.Handler) *Harness {
+	backend := httptest.NewServer(handler)
+	t.Cleanup(backend.Close)
+	backendURL, _ := url.Parse(backend.URL)
+
+	paths := &model.RevelContainer{AppName: appName, Config: config.NewContext()}
+	h := &Harness{
+		paths:      paths,
+		serverHost: backendURL.Host,
+		proxy:      httputil.NewSingleHostReverseProxy(backendURL),
+		mutex:      &sync.Mutex{},
+		watcher:    watcher.NewWatcher(paths, false),
+	}
+	return h
+}
+
+// Test that the profiles are forwarded to the pprof endpoints of the app, without reaching the app
+// itself.
+func TestServePprof(t *testing.T) {
+	h := newTestHarness(t, "web", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("The app served %s", r.URL.Path)
+	}))
+
+	// Without --pprof there is nothing to forward to
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PprofPath+"heap", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "--pprof")
+
+	pprof := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s?%s", r.URL.Path, r.URL.RawQuery)
+	}))
+	defer pprof.Close()
+	pprofURL, _ := url.Parse(pprof.URL)
+	h.pprofProxy = httputil.NewSingleHostReverseProxy(pprofURL)
+
+	for target, expected := range map[string]string{
+		PprofPath:                       "/debug/pprof/?",
+		PprofPath + "heap?gc=1":         "/debug/pprof/heap?gc=1",
+		PprofPath + "profile?seconds=5": "/debug/pprof/profile?seconds=5",
+	} {
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		body, _ := ioutil.ReadAll(recorder.Body)
+		assert.Equal(t, http.StatusOK, recorder.Code, target)
+		assert.Equal(t, expected, string(body), target)
+	}
+}
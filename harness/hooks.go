@@ -0,0 +1,52 @@
+package harness
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+// RunHooks raises the event for the handlers added with model.AddInitEventHandler, and runs the
+// shell command configured for it in app.conf, e.g. "hook.build.pre = make assets". The command
+// runs in the application folder with the event in the environment. The output of a failing
+// command is returned in the error, so it is shown on the error page.
+func RunHooks(paths *model.RevelContainer, event model.Event, value interface{}) error {
+	model.RaiseEvent(event, value)
+
+	key := model.EventHooks[event]
+	command := strings.TrimSpace(paths.Config.StringDefault(key, ""))
+	if key == "" || command == "" {
+		return nil
+	}
+
+	cmd := utils.ShellCmd(command)
+	cmd.Dir = paths.BasePath
+	cmd.Env = append(os.Environ(),
+		"REVEL_EVENT="+strings.TrimPrefix(key, "hook."),
+		"REVEL_IMPORT_PATH="+paths.ImportPath,
+		"REVEL_APP_PATH="+paths.BasePath,
+		"REVEL_RUN_MODE="+paths.RunMode,
+	)
+	if path, ok := value.(string); ok {
+		cmd.Env = append(cmd.Env, "REVEL_EVENT_VALUE="+path)
+	}
+	// The same writer is used for both, so the output is written by one goroutine in order
+	output := &bytes.Buffer{}
+	cmd.Stdout = io.MultiWriter(os.Stdout, output)
+	cmd.Stderr = cmd.Stdout
+
+	utils.Logger.Info("Running hook", "hook", key, "command", command)
+	if err := cmd.Run(); err != nil {
+		return &utils.SourceError{
+			Title:       "Hook Failed",
+			Description: fmt.Sprintf("The %s hook '%s' failed: %v", key, command, err),
+			Stack:       output.String(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package harness_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates an application folder with the hooks set in its config.
+func newHookApp(t *testing.T, hooks map[string]string) *model.RevelContainer {
+	if runtime.GOOS == "windows" {
+		t.Skip("The hooks of the tests are shell commands")
+	}
+	basePath, err := ioutil.TempDir("", "revel-hooks")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(basePath) })
+	// The folder is compared with the working directory of the hook, which has no symlinks
+	basePath, err = filepath.EvalSymlinks(basePath)
+	assert.Nil(t, err)
+
+	paths := &model.RevelContainer{
+		ImportPath: "example.com/app",
+		BasePath:   basePath,
+		RunMode:    "dev",
+		Config:     config.NewContext(),
+	}
+	for key, command := range hooks {
+		paths.Config.SetOption(key, command)
+	}
+	return paths
+}
+
+func TestRunHooks(t *testing.T) {
+	paths := newHookApp(t, map[string]string{
+		"hook.build.post": "pwd > hook.out; env | grep ^REVEL_ | sort >> hook.out",
+	})
+	assert.Nil(t, harness.RunHooks(paths, model.BUILD_STARTED, paths), "No hook is set for the event")
+	assert.False(t, utils.Exists(filepath.Join(paths.BasePath, "hook.out")))
+
+	assert.Nil(t, harness.RunHooks(paths, model.BUILD_COMPLETED, "/tmp/app"))
+	output, err := ioutil.ReadFile(filepath.Join(paths.BasePath, "hook.out"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		paths.BasePath,
+		"REVEL_APP_PATH=" + paths.BasePath,
+		"REVEL_EVENT=build.post",
+		"REVEL_EVENT_VALUE=/tmp/app",
+		"REVEL_IMPORT_PATH=example.com/app",
+		"REVEL_RUN_MODE=dev",
+	}, strings.Split(strings.TrimSpace(string(output)), "\n"))
+}
+
+func TestRunHooksFailure(t *testing.T) {
+	paths := newHookApp(t, map[string]string{
+		"hook.package.pre": "echo output; echo error >&2; exit 3",
+	})
+	err := harness.RunHooks(paths, model.PACKAGE_STARTED, paths)
+	sourceError, ok := err.(*utils.SourceError)
+	if !assert.True(t, ok, "The error should be a source error: %v", err) {
+		return
+	}
+	assert.Equal(t, "Hook Failed", sourceError.Title)
+	assert.Contains(t, sourceError.Description, "hook.package.pre")
+	assert.Contains(t, sourceError.Description, "exit status 3")
+	assert.Equal(t, "output\nerror\n", sourceError.Stack)
+}
+
+// Test that the app is stopped when the hook run once it listens fails.
+func TestAppStartHookFailure(t *testing.T) {
+	paths := newHookApp(t, map[string]string{"hook.app.start": "exit 1"})
+	binPath := filepath.Join(paths.BasePath, "app")
+	assert.Nil(t, ioutil.WriteFile(binPath, []byte("#!/bin/sh\necho 'Revel engine is listening on localhost'\nexec sleep 60\n"), 0755))
+
+	c := &model.CommandConfig{AppPath: paths.BasePath}
+	cmd := harness.NewAppCmd(binPath, 9000, "dev", paths)
+	err := cmd.Start(c)
+	if !assert.NotNil(t, err) {
+		cmd.Kill()
+		return
+	}
+	assert.Contains(t, err.Error(), "hook.app.start")
+	assert.NotNil(t, cmd.Process.Signal(syscall.Signal(0)), "The app should not be running")
+}
@@ -0,0 +1,98 @@
+package harness
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Responds with the protocol of the request.
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, r.Proto)
+})
+
+// Returns a container with the options set in its config.
+func newHTTP2Paths(options map[string]string) *model.RevelContainer {
+	paths := &model.RevelContainer{Config: config.NewContext()}
+	for name, value := range options {
+		paths.Config.SetOption(name, value)
+	}
+	return paths
+}
+
+// Sends a GET to the url with the transport, returns the body.
+func getProto(t *testing.T, transport http.RoundTripper, url string) string {
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+	response, err := client.Get(url)
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	return string(body)
+}
+
+func TestBackendTransport(t *testing.T) {
+	assert.Nil(t, backendTransport(newHTTP2Paths(nil)), "The default transport should be used")
+
+	// Cleartext HTTP/2 with prior knowledge
+	backend := httptest.NewServer(h2c.NewHandler(protoHandler, &http2.Server{}))
+	defer backend.Close()
+	transport := backendTransport(newHTTP2Paths(map[string]string{"harness.backend.http2": "true"}))
+	assert.Equal(t, "HTTP/2.0", getProto(t, transport, backend.URL))
+
+	// HTTP/2 over TLS, the certificate of the application is not verified
+	tlsBackend := httptest.NewUnstartedServer(protoHandler)
+	tlsBackend.EnableHTTP2 = true
+	tlsBackend.StartTLS()
+	defer tlsBackend.Close()
+	paths := newHTTP2Paths(map[string]string{"harness.backend.http2": "true"})
+	paths.HTTPSsl = true
+	assert.Equal(t, "HTTP/2.0", getProto(t, backendTransport(paths), tlsBackend.URL))
+	paths = newHTTP2Paths(nil)
+	paths.HTTPSsl = true
+	assert.Equal(t, "HTTP/1.1", getProto(t, backendTransport(paths), tlsBackend.URL))
+}
+
+// Starts the proxy server on a free port, returns the url once it accepts connections.
+func startListen(t *testing.T, paths *model.RevelContainer, handler http.Handler) string {
+	paths.HTTPAddr, paths.HTTPPort = "127.0.0.1", getFreePort()
+	go listen(paths, handler)
+	addr := net.JoinHostPort(paths.HTTPAddr, fmt.Sprint(paths.HTTPPort))
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+	}
+	return "http://" + addr
+}
+
+func TestListenH2C(t *testing.T) {
+	priorKnowledge := &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}
+
+	url := startListen(t, newHTTP2Paths(map[string]string{"harness.h2c": "true"}), protoHandler)
+	assert.Equal(t, "HTTP/2.0", getProto(t, priorKnowledge, url))
+	assert.Equal(t, "HTTP/1.1", getProto(t, &http.Transport{}, url), "HTTP/1.1 should still be served")
+
+	url = startListen(t, newHTTP2Paths(nil), protoHandler)
+	assert.Equal(t, "HTTP/1.1", getProto(t, &http.Transport{}, url))
+	_, err := (&http.Client{Transport: priorKnowledge, Timeout: 10 * time.Second}).Get(url)
+	assert.NotNil(t, err, "h2c should be off by default")
+}
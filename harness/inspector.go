@@ -0,0 +1,448 @@
+package harness
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/revel/cmd"
+	"github.com/revel/cmd/utils"
+)
+
+// InspectorPath is the path of the proxy which shows the requests recorded by the inspector,
+// when the application is run with --inspect.
+const InspectorPath = "/@harness/requests"
+
+type (
+	// Inspector keeps the recent requests proxied by the harness and their responses in a ring
+	// buffer, the bodies are truncated to a limit.
+	Inspector struct {
+		mutex     sync.Mutex
+		requests  []*InspectedRequest // The ring buffer
+		next      int                 // The index the next request is stored at
+		lastID    int                 // The id of the last request
+		bodyLimit int                 // The number of bytes kept of the bodies
+	}
+
+	// InspectedRequest is a request recorded by the inspector.
+	InspectedRequest struct {
+		ID                int
+		ReplayOf          int // The id of the request this is a replay of
+		Started           time.Time
+		Duration          time.Duration
+		Method            string
+		URL               string // The URL as requested from the proxy
+		RequestURI        string // The URI as forwarded to the application
+		Proto             string
+		Host              string
+		RequestHeader     http.Header
+		RequestBody       []byte
+		RequestTruncated  bool
+		Status            int
+		ResponseHeader    http.Header
+		ResponseBody      []byte
+		ResponseTruncated bool
+	}
+
+	// Records the response written to the proxy.
+	recordingWriter struct {
+		http.ResponseWriter
+		request *InspectedRequest
+		limit   int
+	}
+
+	// A response writer which drops the response of a replayed request, the inspector keeps it.
+	discardWriter struct {
+		header http.Header
+	}
+)
+
+// NewInspector returns an inspector which keeps the number of requests, at least one.
+func NewInspector(size, bodyLimit int) *Inspector {
+	if size < 1 {
+		size = 1
+	}
+	if bodyLimit < 0 {
+		bodyLimit = 0
+	}
+	return &Inspector{requests: make([]*InspectedRequest, size), bodyLimit: bodyLimit}
+}
+
+// Record starts recording the request, the returned writer records the response until done is
+// called. The request body is read up to the limit and put back, so the application receives
+// all of it.
+func (i *Inspector) Record(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	request := &InspectedRequest{
+		Started:       time.Now(),
+		Method:        r.Method,
+		URL:           requestURL(r),
+		RequestURI:    r.URL.RequestURI(),
+		Proto:         r.Proto,
+		Host:          r.Host,
+		RequestHeader: r.Header.Clone(),
+	}
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(i.bodyLimit)+1))
+		if err != nil {
+			utils.Logger.Warn("Inspector failed to read the request body", "error", err)
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		request.RequestBody, request.RequestTruncated = truncate(body, i.bodyLimit)
+	}
+
+	writer := &recordingWriter{ResponseWriter: w, request: request, limit: i.bodyLimit}
+	return writer, func() {
+		request.Duration = time.Since(request.Started)
+		if request.Status == 0 {
+			request.Status = http.StatusOK
+		}
+		request.ResponseHeader = w.Header().Clone()
+		i.add(request)
+	}
+}
+
+// Adds the request to the ring buffer, replacing the oldest one if it is full.
+func (i *Inspector) add(request *InspectedRequest) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.lastID++
+	if request.ID == 0 {
+		request.ID = i.lastID
+	}
+	i.requests[i.next] = request
+	i.next = (i.next + 1) % len(i.requests)
+}
+
+// Requests returns the recorded requests, the latest first.
+func (i *Inspector) Requests() (requests []*InspectedRequest) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, request := range i.requests {
+		if request != nil {
+			requests = append(requests, request)
+		}
+	}
+	sort.Slice(requests, func(a, b int) bool { return requests[a].ID > requests[b].ID })
+	return
+}
+
+// Request returns the recorded request with the id, nil if it is no longer kept.
+func (i *Inspector) Request(id int) *InspectedRequest {
+	for _, request := range i.Requests() {
+		if request.ID == id {
+			return request
+		}
+	}
+	return nil
+}
+
+// ServeHTTP shows the recorded requests, exports them as HAR (InspectorPath.har) and replays a
+// request (a POST to InspectorPath/<id>/replay) through the handler, which rebuilds the
+// application first if the code changed.
+func (i *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request, handler http.Handler) {
+	path := strings.TrimPrefix(r.URL.Path, InspectorPath)
+	switch {
+	case path == "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := inspectorTemplate.Execute(w, i.Requests()); err != nil {
+			utils.Logger.Error("Failed to render the inspector", "error", err)
+		}
+	case path == ".har":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="requests.har"`)
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(i.HAR()); err != nil {
+			utils.Logger.Error("Failed to write the HAR", "error", err)
+		}
+	case strings.HasSuffix(path, "/replay") && r.Method == http.MethodPost:
+		id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/replay"))
+		request := i.Request(id)
+		if request == nil {
+			http.Error(w, "The request is no longer recorded", http.StatusNotFound)
+			return
+		}
+		i.replay(request, handler)
+		// Back to the list, relative so it works behind a path prefix
+		w.Header().Set("Location", "../../requests")
+		w.WriteHeader(http.StatusSeeOther)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Sends the request again, the response is recorded as a new request.
+func (i *Inspector) replay(original *InspectedRequest, handler http.Handler) {
+	r, err := http.NewRequest(original.Method, original.RequestURI, bytes.NewReader(original.RequestBody))
+	if err != nil {
+		utils.Logger.Error("Failed to replay the request", "id", original.ID, "error", err)
+		return
+	}
+	r.Header = original.RequestHeader.Clone()
+	r.Host = original.Host
+	r.Header.Del("Content-Length")
+	r.ContentLength = int64(len(original.RequestBody))
+
+	w, done := i.Record(&discardWriter{header: http.Header{}}, r)
+	w.(*recordingWriter).request.ReplayOf = original.ID
+	w.(*recordingWriter).request.URL = original.URL
+	handler.ServeHTTP(w, r)
+	done()
+}
+
+// WriteHeader records the status.
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.request.Status == 0 {
+		w.request.Status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body up to the limit.
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	if w.request.Status == 0 {
+		w.request.Status = http.StatusOK
+	}
+	if len(w.request.ResponseBody)+len(data) > w.limit {
+		w.request.ResponseTruncated = true
+	}
+	if room := w.limit - len(w.request.ResponseBody); room > 0 {
+		w.request.ResponseBody = append(w.request.ResponseBody, data[:minInt(room, len(data))]...)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends the buffered response, so streamed responses are not held back.
+func (w *recordingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *discardWriter) Header() http.Header            { return w.header }
+func (w *discardWriter) Write(data []byte) (int, error) { return len(data), nil }
+func (w *discardWriter) WriteHeader(int)                {}
+
+// Returns the URL of the request as the browser requested it, with the path prefix the
+// applications behind one proxy are routed by.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.Header.Get("X-Forwarded-Prefix") + r.URL.RequestURI()
+}
+
+// Returns the body truncated to the limit.
+func truncate(body []byte, limit int) ([]byte, bool) {
+	if len(body) > limit {
+		return body[:limit], true
+	}
+	return body, false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type (
+	// The HTTP Archive format, see http://www.softwareishard.com/blog/har-12-spec/
+	harLog struct {
+		Log struct {
+			Version string     `json:"version"`
+			Creator harCreator `json:"creator"`
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+		Comment         string      `json:"comment,omitempty"`
+	}
+	harRequest struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		PostData    *harPostData   `json:"postData,omitempty"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+	harResponse struct {
+		Status      int            `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		Content     harContent     `json:"content"`
+		RedirectURL string         `json:"redirectURL"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+	harNameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	harPostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+	harContent struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+		Encoding string `json:"encoding,omitempty"`
+	}
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// HAR returns the recorded requests in the HTTP Archive format, the oldest first.
+func (i *Inspector) HAR() *harLog {
+	har := &harLog{}
+	har.Log.Version = "1.2"
+	har.Log.Creator = harCreator{Name: "revel", Version: cmd.Version}
+	har.Log.Entries = []harEntry{}
+	requests := i.Requests()
+	for index := len(requests) - 1; index >= 0; index-- {
+		request := requests[index]
+		milliseconds := float64(request.Duration) / float64(time.Millisecond)
+		entry := harEntry{
+			StartedDateTime: request.Started.Format(time.RFC3339Nano),
+			Time:            milliseconds,
+			Request: harRequest{
+				Method:      request.Method,
+				URL:         request.URL,
+				HTTPVersion: request.Proto,
+				Cookies:     []harNameValue{},
+				Headers:     harHeaders(request.RequestHeader),
+				QueryString: []harNameValue{},
+				HeadersSize: -1,
+				BodySize:    len(request.RequestBody),
+			},
+			Response: harResponse{
+				Status:      request.Status,
+				StatusText:  http.StatusText(request.Status),
+				HTTPVersion: request.Proto,
+				Cookies:     []harNameValue{},
+				Headers:     harHeaders(request.ResponseHeader),
+				Content:     harContent{Size: len(request.ResponseBody), MimeType: request.ResponseHeader.Get("Content-Type")},
+				RedirectURL: request.ResponseHeader.Get("Location"),
+				HeadersSize: -1,
+				BodySize:    len(request.ResponseBody),
+			},
+			Timings: harTimings{Wait: milliseconds},
+		}
+		if query := strings.SplitN(request.RequestURI, "?", 2); len(query) > 1 {
+			for _, pair := range strings.Split(query[1], "&") {
+				nameValue := strings.SplitN(pair, "=", 2)
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: nameValue[0], Value: strings.Join(nameValue[1:], "")})
+			}
+		}
+		if len(request.RequestBody) > 0 {
+			entry.Request.PostData = &harPostData{MimeType: request.RequestHeader.Get("Content-Type"), Text: string(request.RequestBody)}
+		}
+		if utf8.Valid(request.ResponseBody) {
+			entry.Response.Content.Text = string(request.ResponseBody)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(request.ResponseBody)
+			entry.Response.Content.Encoding = "base64"
+		}
+		if request.RequestTruncated || request.ResponseTruncated {
+			entry.Comment = "The bodies are truncated"
+		}
+		har.Log.Entries = append(har.Log.Entries, entry)
+	}
+	return har
+}
+
+// Returns the headers sorted by name.
+func harHeaders(header http.Header) (headers []harNameValue) {
+	headers = []harNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(headers, func(a, b int) bool { return headers[a].Name < headers[b].Name })
+	return
+}
+
+var inspectorTemplate = template.Must(template.New("requests").Funcs(template.FuncMap{
+	"milliseconds": func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 1, 64)
+	},
+	"text": func(body []byte) string { return string(body) },
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Requests</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 1em 2em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; vertical-align: top; }
+pre { background: #f6f6f6; padding: 8px; max-height: 20em; overflow: auto; white-space: pre-wrap; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Requests</h1>
+<p><a href="requests.har">Download as HAR</a> &middot; <a href="requests">Refresh</a></p>
+<table>
+<tr><th>#</th><th>Time</th><th>Method</th><th>URL</th><th>Status</th><th>ms</th><th></th></tr>
+{{range .}}
+<tr>
+<td>{{.ID}}{{if .ReplayOf}} (replay of {{.ReplayOf}}){{end}}</td>
+<td>{{.Started.Format "15:04:05"}}</td>
+<td>{{.Method}}</td>
+<td>
+<details><summary>{{.URL}}</summary>
+<h4>Request headers</h4><pre>{{range $name, $values := .RequestHeader}}{{$name}}: {{join $values ", "}}
+{{end}}</pre>
+{{if .RequestBody}}<h4>Request body{{if .RequestTruncated}} (truncated){{end}}</h4><pre>{{text .RequestBody}}</pre>{{end}}
+<h4>Response headers</h4><pre>{{range $name, $values := .ResponseHeader}}{{$name}}: {{join $values ", "}}
+{{end}}</pre>
+{{if .ResponseBody}}<h4>Response body{{if .ResponseTruncated}} (truncated){{end}}</h4><pre>{{text .ResponseBody}}</pre>{{end}}
+</details>
+</td>
+<td{{if ge .Status 400}} class="error"{{end}}>{{.Status}}</td>
+<td>{{milliseconds .Duration}}</td>
+<td>{{if not .RequestTruncated}}<form method="post" action="requests/{{.ID}}/replay"><button>Replay</button></form>{{end}}</td>
+</tr>
+{{else}}
+<tr><td colspan="7">No requests recorded yet.</td></tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,199 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Records a request for the path through the inspector, the handler writes the response.
+func recordRequest(i *Inspector, method, target, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	w, done := i.Record(recorder, r)
+	handler(w, r)
+	done()
+	return recorder
+}
+
+func TestInspectorRing(t *testing.T) {
+	i := NewInspector(3, 1024)
+	for n := 1; n <= 5; n++ {
+		recordRequest(i, http.MethodGet, fmt.Sprintf("/page/%d", n), "", func(w http.ResponseWriter, r *http.Request) {})
+	}
+
+	// The oldest requests are replaced, the latest is first
+	requests := i.Requests()
+	if assert.Len(t, requests, 3) {
+		assert.Equal(t, []int{5, 4, 3}, []int{requests[0].ID, requests[1].ID, requests[2].ID})
+		assert.Equal(t, "/page/5", requests[0].RequestURI)
+		assert.Equal(t, http.StatusOK, requests[0].Status)
+	}
+	assert.Nil(t, i.Request(2))
+	assert.NotNil(t, i.Request(3))
+}
+
+func TestInspectorSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		i := NewInspector(size, -1)
+		recordRequest(i, http.MethodPost, "/", "body", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "response")
+		})
+		requests := i.Requests()
+		if assert.Len(t, requests, 1, "size %d", size) {
+			assert.True(t, requests[0].RequestTruncated)
+			assert.True(t, requests[0].ResponseTruncated)
+		}
+	}
+}
+
+func TestInspectorTruncation(t *testing.T) {
+	i := NewInspector(10, 8)
+
+	// The application receives the whole body, the inspector keeps the limit
+	var received string
+	recordRequest(i, http.MethodPost, "/", "0123456789", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+	})
+	assert.Equal(t, "0123456789", received)
+	request := i.Requests()[0]
+	assert.Equal(t, "01234567", string(request.RequestBody))
+	assert.True(t, request.RequestTruncated)
+
+	for _, test := range []struct {
+		chunks    []string
+		body      string
+		truncated bool
+	}{
+		{[]string{"0123", "4567"}, "01234567", false},
+		{[]string{"01234567"}, "01234567", false},
+		{[]string{"0123", "45678"}, "01234567", true},
+		{[]string{"01234567", ""}, "01234567", false},
+		{[]string{"01234567", "8"}, "01234567", true},
+		{[]string{"012", "345", "67"}, "01234567", false},
+	} {
+		recorder := recordRequest(i, http.MethodGet, "/", "", func(w http.ResponseWriter, r *http.Request) {
+			for _, chunk := range test.chunks {
+				_, _ = w.Write([]byte(chunk))
+			}
+		})
+		request := i.Requests()[0]
+		assert.Equal(t, strings.Join(test.chunks, ""), recorder.Body.String(), "The client should receive all of it")
+		assert.Equal(t, test.body, string(request.ResponseBody), "%q", test.chunks)
+		assert.Equal(t, test.truncated, request.ResponseTruncated, "%q", test.chunks)
+		assert.False(t, request.RequestTruncated)
+	}
+}
+
+func TestInspectorHAR(t *testing.T) {
+	i := NewInspector(10, 1024)
+	recordRequest(i, http.MethodGet, "http://localhost/users?page=2&sort", "", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "not found")
+	})
+	recordRequest(i, http.MethodPost, "http://localhost/users", `{"name":"revel"}`, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/users/1")
+		w.WriteHeader(http.StatusFound)
+		_, _ = w.Write([]byte{0xff, 0xfe})
+	})
+
+	w := httptest.NewRecorder()
+	i.ServeHTTP(w, httptest.NewRequest(http.MethodGet, InspectorPath+".har", nil), nil)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	har := &harLog{}
+	if !assert.Nil(t, json.Unmarshal(w.Body.Bytes(), har)) || !assert.Len(t, har.Log.Entries, 2) {
+		return
+	}
+	assert.Equal(t, "1.2", har.Log.Version)
+
+	// The oldest request is first
+	get, post := har.Log.Entries[0], har.Log.Entries[1]
+	assert.Equal(t, "GET", get.Request.Method)
+	assert.Equal(t, "http://localhost/users?page=2&sort", get.Request.URL)
+	assert.Equal(t, []harNameValue{{Name: "page", Value: "2"}, {Name: "sort"}}, get.Request.QueryString)
+	assert.Nil(t, get.Request.PostData)
+	assert.Equal(t, 404, get.Response.Status)
+	assert.Equal(t, "Not Found", get.Response.StatusText)
+	assert.Equal(t, harContent{Size: 9, MimeType: "text/plain", Text: "not found"}, get.Response.Content)
+
+	assert.Equal(t, &harPostData{Text: `{"name":"revel"}`}, post.Request.PostData)
+	assert.Equal(t, "/users/1", post.Response.RedirectURL)
+	assert.Equal(t, "base64", post.Response.Content.Encoding)
+	assert.Equal(t, "//4=", post.Response.Content.Text)
+	assert.Equal(t, "", post.Comment)
+}
+
+func TestInspectorReplay(t *testing.T) {
+	i := NewInspector(10, 1024)
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "%d %s %s %s %s", calls, r.Method, r.URL.RequestURI(), r.Header.Get("X-Test"), body)
+	})
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/users?id=1", strings.NewReader("name=revel"))
+	r.Header.Set("X-Test", "header")
+	recorder := httptest.NewRecorder()
+	w, done := i.Record(recorder, r)
+	handler.ServeHTTP(w, r)
+	done()
+
+	// A replay of a request which is no longer kept is not found
+	w2 := httptest.NewRecorder()
+	i.ServeHTTP(w2, httptest.NewRequest(http.MethodPost, InspectorPath+"/9/replay", nil), handler)
+	assert.Equal(t, http.StatusNotFound, w2.Code)
+
+	w2 = httptest.NewRecorder()
+	i.ServeHTTP(w2, httptest.NewRequest(http.MethodPost, InspectorPath+"/1/replay", nil), handler)
+	assert.Equal(t, http.StatusSeeOther, w2.Code)
+	assert.Equal(t, "../../requests", w2.Header().Get("Location"))
+	assert.Equal(t, 2, calls)
+
+	// The replayed response is recorded as a new request
+	replay := i.Request(2)
+	if assert.NotNil(t, replay) {
+		assert.Equal(t, 1, replay.ReplayOf)
+		assert.Equal(t, "http://localhost/users?id=1", replay.URL)
+		assert.Equal(t, "2 POST /users?id=1 header name=revel", string(replay.ResponseBody))
+	}
+
+	// The list links to the replay
+	w2 = httptest.NewRecorder()
+	i.ServeHTTP(w2, httptest.NewRequest(http.MethodGet, InspectorPath, nil), handler)
+	assert.Contains(t, w2.Body.String(), "2 (replay of 1)")
+	assert.Contains(t, w2.Body.String(), `action="requests/1/replay"`)
+}
+
+// Test that the harness records the proxied requests and replays them through the application.
+func TestInspectorHarness(t *testing.T) {
+	calls := 0
+	h := newTestHarness(t, "app", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, "call %d", calls)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, InspectorPath, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "The inspector is only served with --inspect")
+
+	h.inspector = NewInspector(10, 1024)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, InspectorPath+"/1/replay", nil))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	requests := h.inspector.Requests()
+	if assert.Len(t, requests, 2, "The inspector pages should not be recorded") {
+		assert.Equal(t, "call 2", string(requests[0].ResponseBody))
+		assert.Equal(t, 1, requests[0].ReplayOf)
+		assert.Equal(t, "call 1", string(requests[1].ResponseBody))
+	}
+}
@@ -0,0 +1,102 @@
+package harness
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/revel/cmd/model"
+)
+
+type (
+	// MultiHarness runs several applications behind one proxy. Each application has its own
+	// harness, which watches and rebuilds it independently and renders its own error pages.
+	MultiHarness struct {
+		paths  *model.RevelContainer // The container which configures the proxy address
+		routes []*routedHarness      // The applications in the order they were added
+	}
+
+	// The harness of an application and the route of its requests.
+	routedHarness struct {
+		route   model.AppRoute
+		harness *Harness
+	}
+)
+
+// NewMultiHarness returns the proxy for several applications, it listens on the address of
+// the container.
+func NewMultiHarness(paths *model.RevelContainer) *MultiHarness {
+	return &MultiHarness{paths: paths}
+}
+
+// Add routes the requests matching the route to the harness.
+func (m *MultiHarness) Add(route model.AppRoute, h *Harness) {
+	h.name = h.paths.AppName
+	m.routes = append(m.routes, &routedHarness{route: route, harness: h})
+}
+
+// ServeHTTP forwards the request to the application of the host name, or else to the
+// application with the longest matching path prefix. The prefix is removed from the path, the
+// application receives it in the X-Forwarded-Prefix header.
+func (m *MultiHarness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := m.match(r)
+	if target == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "No application is routed for %s%s, the applications are:\n", r.Host, r.URL.Path)
+		for _, routed := range m.routes {
+			fmt.Fprintf(w, "  %s %s\n", routed.route, routed.harness.paths.AppName)
+		}
+		return
+	}
+
+	if prefix := target.route.Prefix; target.route.Host == "" && prefix != "/" {
+		r = r.Clone(r.Context())
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if r.URL.Path == "" {
+			r.URL.Path = "/"
+		}
+		r.URL.RawPath = ""
+		r.Header.Set("X-Forwarded-Prefix", prefix)
+	}
+	target.harness.ServeHTTP(w, r)
+}
+
+// Returns the application the request is routed to, or nil.
+func (m *MultiHarness) match(r *http.Request) (target *routedHarness) {
+	for _, routed := range m.routes {
+		if !routed.route.Matches(r.Host, r.URL.Path) {
+			continue
+		}
+		if routed.route.Host != "" {
+			return routed
+		}
+		if target == nil || len(routed.route.Prefix) > len(target.route.Prefix) {
+			target = routed
+		}
+	}
+	return
+}
+
+// Run builds and watches every application and starts the proxy.
+func (m *MultiHarness) Run() {
+	harnesses := m.harnesses()
+	for _, h := range harnesses {
+		h.watch()
+	}
+	go listen(m.paths, m)
+	waitForInterrupt(harnesses...)
+}
+
+// Returns the harness of every application once, an application may have several routes.
+func (m *MultiHarness) harnesses() (harnesses []*Harness) {
+	for _, routed := range m.routes {
+		found := false
+		for _, h := range harnesses {
+			found = found || h == routed.harness
+		}
+		if !found {
+			harnesses = append(harnesses, routed.harness)
+		}
+	}
+	return
+}
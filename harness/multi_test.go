@@ -0,0 +1,98 @@
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a harness for a backend which echoes the application name, path and forwarded prefix.
+func newEchoHarness(t *testing.T, appName string) *Harness {
+	return newTestHarness(t, appName, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", appName, r.URL.Path, r.Header.Get("X-Forwarded-Prefix"))
+	}))
+}
+
+func TestMultiHarnessServeHTTP(t *testing.T) {
+	m := NewMultiHarness(&model.RevelContainer{})
+	m.Add(model.AppRoute{Path: "./web", Prefix: "/"}, newEchoHarness(t, "web"))
+	m.Add(model.AppRoute{Path: "./api", Prefix: "/api"}, newEchoHarness(t, "api"))
+	m.Add(model.AppRoute{Path: "./apiv2", Prefix: "/api/v2"}, newEchoHarness(t, "apiv2"))
+	m.Add(model.AppRoute{Path: "./admin", Host: "admin.example.com"}, newEchoHarness(t, "admin"))
+
+	for _, test := range []struct {
+		host, path, expected string
+	}{
+		{"localhost:9000", "/", "web / "},
+		{"localhost:9000", "/users/1", "web /users/1 "},
+		{"localhost:9000", "/api", "api / /api"},
+		{"localhost:9000", "/api/users", "api /users /api"},
+		{"localhost:9000", "/apiary", "web /apiary "},
+		// The longest prefix wins, whatever the order the applications were added in
+		{"localhost:9000", "/api/v2/users", "apiv2 /users /api/v2"},
+		// The host name wins over the prefixes, and the path is left alone
+		{"admin.example.com:9000", "/api/users", "admin /api/users "},
+		{"ADMIN.example.com", "/", "admin / "},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "http://"+test.host+test.path, nil)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		assert.Equal(t, http.StatusOK, w.Code, test.host+test.path)
+		assert.Equal(t, test.expected, string(body), test.host+test.path)
+	}
+}
+
+func TestMultiHarnessNotRouted(t *testing.T) {
+	m := NewMultiHarness(&model.RevelContainer{})
+	m.Add(model.AppRoute{Path: "./api", Prefix: "/api"}, newEchoHarness(t, "api"))
+	m.Add(model.AppRoute{Path: "./admin", Host: "admin.example.com"}, newEchoHarness(t, "admin"))
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost/users", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "No application is routed for localhost/users")
+	assert.Contains(t, w.Body.String(), "/api api")
+	assert.Contains(t, w.Body.String(), "http://admin.example.com/ admin")
+}
+
+// Test that the original request is not changed when the prefix is removed.
+func TestMultiHarnessClone(t *testing.T) {
+	m := NewMultiHarness(&model.RevelContainer{})
+	m.Add(model.AppRoute{Path: "./api", Prefix: "/api"}, newEchoHarness(t, "api"))
+
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/api/users/1", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	assert.Equal(t, "api /users/1 /api", w.Body.String())
+	assert.Equal(t, "/api/users/1", r.URL.Path)
+	assert.Equal(t, "", r.Header.Get("X-Forwarded-Prefix"))
+}
+
+// Test that an application with several routes is built and watched once, and served on each route.
+func TestMultiHarnessRoutesOfOneApp(t *testing.T) {
+	m := NewMultiHarness(&model.RevelContainer{})
+	web, api := newEchoHarness(t, "web"), newEchoHarness(t, "api")
+	m.Add(model.AppRoute{Path: "./web", Prefix: "/"}, web)
+	m.Add(model.AppRoute{Path: "./api", Prefix: "/api"}, api)
+	m.Add(model.AppRoute{Path: "./web", Host: "www.example.com"}, web)
+
+	harnesses := m.harnesses()
+	assert.Len(t, harnesses, 2)
+	assert.True(t, harnesses[0] == web && harnesses[1] == api, "The harnesses should be in the order they were added")
+
+	for url, expected := range map[string]string{
+		"http://localhost/":          "web / ",
+		"http://localhost/api":       "api / /api",
+		"http://www.example.com/api": "web /api ",
+	} {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Equal(t, expected, w.Body.String(), url)
+	}
+}
@@ -0,0 +1,459 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+type (
+	// OpenAPIDocument is the root of an OpenAPI 3 document.
+	OpenAPIDocument struct {
+		OpenAPI    string                     `json:"openapi" yaml:"openapi"`
+		Info       OpenAPIInfo                `json:"info" yaml:"info"`
+		Servers    []OpenAPIServer            `json:"servers,omitempty" yaml:"servers,omitempty"`
+		Paths      map[string]OpenAPIPathItem `json:"paths" yaml:"paths"`
+		Components OpenAPIComponents          `json:"components,omitempty" yaml:"components,omitempty"`
+	}
+	// OpenAPIInfo describes the application.
+	OpenAPIInfo struct {
+		Title   string `json:"title" yaml:"title"`
+		Version string `json:"version" yaml:"version"`
+	}
+	// OpenAPIServer is the url the application is served from.
+	OpenAPIServer struct {
+		URL string `json:"url" yaml:"url"`
+	}
+	// OpenAPIPathItem maps the lower case http method to the operation.
+	OpenAPIPathItem map[string]*OpenAPIOperation
+	// OpenAPIOperation describes a single controller action.
+	OpenAPIOperation struct {
+		OperationID string                      `json:"operationId" yaml:"operationId"`
+		Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+		Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+		Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Parameters  []*OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+	}
+	// OpenAPIParameter describes a path or query parameter.
+	OpenAPIParameter struct {
+		Name     string         `json:"name" yaml:"name"`
+		In       string         `json:"in" yaml:"in"`
+		Required bool           `json:"required,omitempty" yaml:"required,omitempty"`
+		Schema   *OpenAPISchema `json:"schema" yaml:"schema"`
+	}
+	// OpenAPIRequestBody describes the body of the request.
+	OpenAPIRequestBody struct {
+		Content map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+	}
+	// OpenAPIMediaType holds the schema for a content type.
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema" yaml:"schema"`
+	}
+	// OpenAPIResponse describes a response.
+	OpenAPIResponse struct {
+		Description string `json:"description" yaml:"description"`
+	}
+	// OpenAPIComponents holds the reusable schemas.
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	}
+	// OpenAPISchema is a (subset of a) JSON schema.
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty" yaml:"type,omitempty"`
+		Format               string                    `json:"format,omitempty" yaml:"format,omitempty"`
+		Description          string                    `json:"description,omitempty" yaml:"description,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	}
+
+	// The generator state, keeps track of the parsed packages and the schemas found.
+	openAPIGenerator struct {
+		paths      *model.RevelContainer
+		sourceInfo *model.SourceInfo
+		schemas    map[string]*OpenAPISchema
+		schemaKeys map[string]string // Type (import path + name) to schema key
+		packages   map[string]*ast.Package
+	}
+)
+
+// The schema for the go builtin types.
+var openAPIBuiltinSchemas = map[string]OpenAPISchema{
+	"bool":       {Type: "boolean"},
+	"byte":       {Type: "integer", Format: "int32"},
+	"complex128": {Type: "string"},
+	"complex64":  {Type: "string"},
+	"error":      {Type: "string"},
+	"float32":    {Type: "number", Format: "float"},
+	"float64":    {Type: "number", Format: "double"},
+	"int":        {Type: "integer", Format: "int64"},
+	"int16":      {Type: "integer", Format: "int32"},
+	"int32":      {Type: "integer", Format: "int32"},
+	"int64":      {Type: "integer", Format: "int64"},
+	"int8":       {Type: "integer", Format: "int32"},
+	"rune":       {Type: "integer", Format: "int32"},
+	"string":     {Type: "string"},
+	"uint":       {Type: "integer", Format: "int64"},
+	"uint16":     {Type: "integer", Format: "int32"},
+	"uint32":     {Type: "integer", Format: "int64"},
+	"uint64":     {Type: "integer", Format: "int64"},
+	"uint8":      {Type: "integer", Format: "int32"},
+	"uintptr":    {Type: "integer", Format: "int64"},
+}
+
+// GenerateOpenAPI creates an OpenAPI 3 document from the routes file and the controller actions.
+func GenerateOpenAPI(paths *model.RevelContainer, sourceInfo *model.SourceInfo) (doc *OpenAPIDocument, err error) {
+	routes, err := paths.LoadRoutes()
+	if err != nil {
+		return
+	}
+
+	g := &openAPIGenerator{
+		paths:      paths,
+		sourceInfo: sourceInfo,
+		schemas:    map[string]*OpenAPISchema{},
+		schemaKeys: map[string]string{},
+		packages:   map[string]*ast.Package{},
+	}
+
+	version := getAppVersion(paths)
+	if version == "" {
+		version = "0.0.0"
+	}
+	scheme, addr := "http", paths.HTTPAddr
+	if paths.HTTPSsl {
+		scheme = "https"
+	}
+	if addr == "" {
+		addr = "localhost"
+	}
+
+	doc = &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: paths.AppName, Version: version},
+		Servers: []OpenAPIServer{{URL: fmt.Sprintf("%s://%s:%d", scheme, addr, paths.HTTPPort)}},
+		Paths:   map[string]OpenAPIPathItem{},
+	}
+
+	for _, route := range routes {
+		if route.IsVariable() || route.Method == "WS" {
+			continue
+		}
+		controller, method := g.findAction(route)
+		if method == nil {
+			utils.Logger.Info("Route action not found, skipping", "action", route.Action, "file", route.File, "line", route.Line)
+			continue
+		}
+
+		httpMethod := strings.ToLower(route.Method)
+		if httpMethod == "*" {
+			httpMethod = "get"
+		}
+		path := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = OpenAPIPathItem{}
+		}
+		if _, found := doc.Paths[path][httpMethod]; found {
+			// The first route wins, just like the router
+			continue
+		}
+		doc.Paths[path][httpMethod] = g.operation(route, httpMethod, controller, method)
+	}
+
+	doc.Components.Schemas = g.schemas
+	return
+}
+
+// Returns the controller and action for the route.
+func (g *openAPIGenerator) findAction(route *model.Route) (*model.TypeInfo, *model.MethodSpec) {
+	for _, controller := range g.sourceInfo.ControllerSpecs() {
+		if !strings.EqualFold(controller.StructName, route.ControllerName()) {
+			continue
+		}
+		for _, method := range controller.MethodSpecs {
+			if strings.EqualFold(method.Name, route.MethodName()) {
+				return controller, method
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Builds the operation for the action.
+func (g *openAPIGenerator) operation(route *model.Route, httpMethod string, controller *model.TypeInfo, method *model.MethodSpec) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: controller.StructName + "." + method.Name,
+		Tags:        []string{controller.StructName},
+		Responses:   map[string]*OpenAPIResponse{"200": {Description: "Successful response"}},
+	}
+	if method.Doc != "" {
+		lines := strings.SplitN(method.Doc, "\n", 2)
+		op.Summary = strings.TrimSpace(lines[0])
+		if len(lines) > 1 {
+			op.Description = strings.TrimSpace(lines[1])
+		}
+	}
+
+	pathParams := route.PathParams()
+	hasBody := httpMethod == "post" || httpMethod == "put" || httpMethod == "patch"
+	form := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	for i, arg := range method.Args {
+		// Fixed arguments are supplied by the routes file
+		if i < len(route.FixedArgs) {
+			continue
+		}
+		schema := g.argSchema(arg)
+		switch {
+		case utils.ContainsString(pathParams, arg.Name):
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{Name: arg.Name, In: "path", Required: true, Schema: schema})
+		case hasBody && schema.Ref != "" && op.RequestBody == nil:
+			op.RequestBody = &OpenAPIRequestBody{Content: map[string]*OpenAPIMediaType{
+				"application/json": {Schema: schema},
+			}}
+		case hasBody:
+			form.Properties[arg.Name] = schema
+		default:
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{Name: arg.Name, In: "query", Schema: schema})
+		}
+	}
+	if len(form.Properties) > 0 {
+		if op.RequestBody == nil {
+			op.RequestBody = &OpenAPIRequestBody{Content: map[string]*OpenAPIMediaType{}}
+		}
+		op.RequestBody.Content["application/x-www-form-urlencoded"] = &OpenAPIMediaType{Schema: form}
+	}
+	return op
+}
+
+// Returns the schema for the method argument.
+func (g *openAPIGenerator) argSchema(arg *model.MethodArg) *OpenAPISchema {
+	expr, err := parser.ParseExpr(arg.TypeExpr.Expr)
+	if err != nil {
+		utils.Logger.Warn("Unable to parse argument type", "arg", arg.Name, "type", arg.TypeExpr.Expr, "error", err)
+		return &OpenAPISchema{}
+	}
+	return g.exprSchema(arg.ImportPath, nil, expr)
+}
+
+// Returns the schema for the type expression, the import path and file are used to resolve the types referenced.
+func (g *openAPIGenerator) exprSchema(importPath string, file *ast.File, expr ast.Expr) *OpenAPISchema {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema, found := openAPIBuiltinSchemas[t.Name]; found {
+			return &schema
+		}
+		return g.typeSchema(importPath, t.Name)
+	case *ast.StarExpr:
+		return g.exprSchema(importPath, file, t.X)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.exprSchema(importPath, file, t.Elt)}
+	case *ast.Ellipsis:
+		return &OpenAPISchema{Type: "array", Items: g.exprSchema(importPath, file, t.Elt)}
+	case *ast.MapType:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.exprSchema(importPath, file, t.Value)}
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return &OpenAPISchema{}
+		}
+		return g.typeSchema(g.resolveImport(file, pkgIdent.Name, importPath), t.Sel.Name)
+	case *ast.StructType:
+		return g.structSchema(importPath, file, t)
+	}
+	return &OpenAPISchema{}
+}
+
+// Returns the import path for the package name used in the file.
+func (g *openAPIGenerator) resolveImport(file *ast.File, pkgName, defaultImportPath string) string {
+	if file == nil {
+		// Method arguments are already resolved by the source parser
+		return defaultImportPath
+	}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == pkgName {
+			return importPath
+		}
+	}
+	return pkgName
+}
+
+// Returns a reference to the named type, walking the type definition if it is part of the application.
+func (g *openAPIGenerator) typeSchema(importPath, typeName string) *OpenAPISchema {
+	if importPath == "time" && typeName == "Time" {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	fullName := importPath + "." + typeName
+	if key, found := g.schemaKeys[fullName]; found {
+		return &OpenAPISchema{Ref: "#/components/schemas/" + key}
+	}
+
+	spec, file := g.findType(importPath, typeName)
+	if spec == nil {
+		return &OpenAPISchema{Type: "object"}
+	}
+
+	key := typeName
+	for i := 0; g.schemas[key] != nil; i++ {
+		key = fmt.Sprintf("%s%d", typeName, i)
+	}
+	// Register the key before walking the type so recursive types resolve to the reference
+	g.schemaKeys[fullName] = key
+	g.schemas[key] = &OpenAPISchema{}
+	schema := g.exprSchema(importPath, file, spec.Type)
+	if spec.Doc != nil {
+		schema.Description = strings.TrimSpace(spec.Doc.Text())
+	}
+	g.schemas[key] = schema
+	return &OpenAPISchema{Ref: "#/components/schemas/" + key}
+}
+
+// Builds the object schema for the struct.
+func (g *openAPIGenerator) structSchema(importPath string, file *ast.File, structType *ast.StructType) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	for _, field := range structType.Fields.List {
+		names := []string{}
+		for _, name := range field.Names {
+			if name.IsExported() {
+				names = append(names, name.Name)
+			}
+		}
+		if field.Names == nil {
+			// Embedded struct, pull the fields in from the referenced type
+			embedded := g.exprSchema(importPath, file, field.Type)
+			if embedded.Ref != "" {
+				embedded = g.schemas[embedded.Ref[len("#/components/schemas/"):]]
+			}
+			for name, property := range embedded.Properties {
+				schema.Properties[name] = property
+			}
+			continue
+		}
+
+		for _, name := range names {
+			jsonName := name
+			if field.Tag != nil {
+				tag, _ := strconv.Unquote(field.Tag.Value)
+				if value, found := reflect.StructTag(tag).Lookup("json"); found {
+					value = strings.Split(value, ",")[0]
+					if value == "-" {
+						continue
+					}
+					if value != "" {
+						jsonName = value
+					}
+				}
+			}
+			schema.Properties[jsonName] = g.exprSchema(importPath, file, field.Type)
+		}
+	}
+	return schema
+}
+
+// Finds the type declaration if the import path belongs to the application or one of its modules.
+func (g *openAPIGenerator) findType(importPath, typeName string) (*ast.TypeSpec, *ast.File) {
+	pkg := g.loadPackage(importPath)
+	if pkg == nil {
+		return nil, nil
+	}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != typeName {
+					continue
+				}
+				if typeSpec.Doc == nil {
+					typeSpec.Doc = genDecl.Doc
+				}
+				return typeSpec, file
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Parses the package for the import path, only packages that are found in the application or the
+// modules are loaded.
+func (g *openAPIGenerator) loadPackage(importPath string) *ast.Package {
+	if pkg, found := g.packages[importPath]; found {
+		return pkg
+	}
+	g.packages[importPath] = nil
+
+	dir := ""
+	roots := map[string]string{g.paths.ImportPath: g.paths.BasePath}
+	for rootImport, rootPath := range g.sourceInfo.PackageMap {
+		if rootImport != model.RevelImportPath && rootPath != "" {
+			roots[rootImport] = rootPath
+		}
+	}
+	// The longest root wins, a module may be nested in the application or another module
+	longest := -1
+	for rootImport, rootPath := range roots {
+		if len(rootImport) <= longest {
+			continue
+		}
+		if importPath == rootImport {
+			dir, longest = rootPath, len(rootImport)
+		} else if strings.HasPrefix(importPath, rootImport+"/") {
+			dir, longest = filepath.Join(rootPath, filepath.FromSlash(importPath[len(rootImport)+1:])), len(rootImport)
+		}
+	}
+	if dir == "" || !utils.DirExists(dir) {
+		return nil
+	}
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(f os.FileInfo) bool {
+		return !strings.HasSuffix(f.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		utils.Logger.Warn("Unable to parse package", "path", dir, "error", err)
+		return nil
+	}
+	for name, pkg := range pkgs {
+		if name != "main" && !strings.HasSuffix(name, "_test") {
+			g.packages[importPath] = pkg
+		}
+	}
+	return g.packages[importPath]
+}
+
+// Converts a revel route path to an OpenAPI path, e.g. "/users/:id" to "/users/{id}".
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
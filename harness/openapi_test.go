@@ -0,0 +1,186 @@
+package harness_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const openAPIRoutes = `
+GET     /users              Users.List
+GET     /users/:id          Users.Show
+POST    /users              Users.Create
+PUT     /users/:id          Users.Update
+GET     /missing            Users.Missing
+*       /:controller/:action :controller.:action
+`
+
+const openAPIModels = `package models
+
+import "time"
+
+// User is a user of the application.
+type User struct {
+	Base
+	Name     string    ` + "`json:\"name\"`" + `
+	Password string    ` + "`json:\"-\"`" + `
+	Tags     []string
+	Created  time.Time
+	secret   string
+}
+
+type Base struct {
+	ID int64 ` + "`json:\"id\"`" + `
+}
+`
+
+// Creates an application with the routes and models, and the source info of its controller.
+func newOpenAPIApp(t *testing.T) (*model.RevelContainer, *model.SourceInfo) {
+	basePath, err := ioutil.TempDir("", "revel-openapi")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(basePath) })
+	assert.Nil(t, os.MkdirAll(filepath.Join(basePath, "conf"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(basePath, "app", "models"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(basePath, "conf", "routes"), []byte(openAPIRoutes), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(basePath, "app", "models", "user.go"), []byte(openAPIModels), 0644))
+
+	paths := &model.RevelContainer{
+		ImportPath: "example.com/app",
+		BasePath:   basePath,
+		AppName:    "app",
+		HTTPPort:   9000,
+	}
+	user := model.NewTypeExprFromData("*User", "models", 1, true)
+	sourceInfo := &model.SourceInfo{
+		PackageMap: map[string]string{},
+		StructSpecs: []*model.TypeInfo{{
+			StructName:    "Users",
+			ImportPath:    "example.com/app/app/controllers",
+			PackageName:   "controllers",
+			EmbeddedTypes: []*model.EmbeddedTypeName{{ImportPath: model.RevelImportPath, StructName: "Controller"}},
+			MethodSpecs: []*model.MethodSpec{
+				{Name: "List", Doc: "List returns the users.\nThe users are sorted by name.", Args: []*model.MethodArg{
+					{Name: "page", TypeExpr: model.NewTypeExprFromData("int", "", 0, true)},
+				}},
+				{Name: "Show", Args: []*model.MethodArg{
+					{Name: "id", TypeExpr: model.NewTypeExprFromData("int64", "", 0, true)},
+				}},
+				{Name: "Create", Args: []*model.MethodArg{
+					{Name: "user", TypeExpr: user, ImportPath: "example.com/app/app/models"},
+				}},
+				{Name: "Update", Args: []*model.MethodArg{
+					{Name: "id", TypeExpr: model.NewTypeExprFromData("int64", "", 0, true)},
+					{Name: "name", TypeExpr: model.NewTypeExprFromData("string", "", 0, true)},
+				}},
+			},
+		}},
+	}
+	return paths, sourceInfo
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	paths, sourceInfo := newOpenAPIApp(t)
+	doc, err := harness.GenerateOpenAPI(paths, sourceInfo)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "app", doc.Info.Title)
+	assert.Equal(t, "0.0.0", doc.Info.Version)
+	assert.Equal(t, []harness.OpenAPIServer{{URL: "http://localhost:9000"}}, doc.Servers)
+
+	// The actions which are not found and the variable routes are skipped
+	assert.Len(t, doc.Paths, 2)
+	assert.NotContains(t, doc.Paths, "/missing")
+
+	list := doc.Paths["/users"]["get"]
+	if assert.NotNil(t, list) {
+		assert.Equal(t, "Users.List", list.OperationID)
+		assert.Equal(t, []string{"Users"}, list.Tags)
+		assert.Equal(t, "List returns the users.", list.Summary)
+		assert.Equal(t, "The users are sorted by name.", list.Description)
+		assert.Equal(t, []*harness.OpenAPIParameter{
+			{Name: "page", In: "query", Schema: &harness.OpenAPISchema{Type: "integer", Format: "int64"}},
+		}, list.Parameters)
+		assert.Nil(t, list.RequestBody)
+	}
+
+	show := doc.Paths["/users/{id}"]["get"]
+	if assert.NotNil(t, show) {
+		assert.Equal(t, []*harness.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true, Schema: &harness.OpenAPISchema{Type: "integer", Format: "int64"}},
+		}, show.Parameters)
+	}
+
+	update := doc.Paths["/users/{id}"]["put"]
+	if assert.NotNil(t, update) && assert.NotNil(t, update.RequestBody) {
+		assert.Len(t, update.Parameters, 1)
+		form := update.RequestBody.Content["application/x-www-form-urlencoded"]
+		if assert.NotNil(t, form) {
+			assert.Equal(t, &harness.OpenAPISchema{Type: "string"}, form.Schema.Properties["name"])
+		}
+	}
+}
+
+func TestGenerateOpenAPISchemas(t *testing.T) {
+	paths, sourceInfo := newOpenAPIApp(t)
+	doc, err := harness.GenerateOpenAPI(paths, sourceInfo)
+	assert.Nil(t, err)
+
+	create := doc.Paths["/users"]["post"]
+	if !assert.NotNil(t, create) || !assert.NotNil(t, create.RequestBody) {
+		return
+	}
+	body := create.RequestBody.Content["application/json"]
+	if assert.NotNil(t, body) {
+		assert.Equal(t, "#/components/schemas/User", body.Schema.Ref)
+	}
+
+	user := doc.Components.Schemas["User"]
+	if !assert.NotNil(t, user) {
+		return
+	}
+	assert.Equal(t, "object", user.Type)
+	assert.Equal(t, "User is a user of the application.", user.Description)
+	// The embedded fields are pulled in, the json tags name the properties and the hidden and
+	// unexported fields are left out
+	assert.Equal(t, map[string]*harness.OpenAPISchema{
+		"id":      {Type: "integer", Format: "int64"},
+		"name":    {Type: "string"},
+		"Tags":    {Type: "array", Items: &harness.OpenAPISchema{Type: "string"}},
+		"Created": {Type: "string", Format: "date-time"},
+	}, user.Properties)
+}
+
+func TestGenerateOpenAPIMissingRoutes(t *testing.T) {
+	paths, sourceInfo := newOpenAPIApp(t)
+	assert.Nil(t, os.Remove(filepath.Join(paths.BasePath, "conf", "routes")))
+	_, err := harness.GenerateOpenAPI(paths, sourceInfo)
+	assert.NotNil(t, err)
+}
+
+// Test that the types are read from the module with the longest import path, without the test
+// packages.
+func TestGenerateOpenAPINestedModule(t *testing.T) {
+	paths, sourceInfo := newOpenAPIApp(t)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(paths.BasePath, "app", "models", "user.go"),
+		[]byte("package models\n\ntype User struct {\n\tOld string\n}\n"), 0644))
+
+	modulePath := filepath.Join(paths.BasePath, "modules", "models")
+	assert.Nil(t, os.MkdirAll(modulePath, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(modulePath, "user.go"), []byte(openAPIModels), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(modulePath, "fixtures.go"),
+		[]byte("package models_test\n\ntype User struct {\n\tFixture string\n}\n"), 0644))
+	sourceInfo.PackageMap["example.com/app/app/models"] = modulePath
+
+	doc, err := harness.GenerateOpenAPI(paths, sourceInfo)
+	assert.Nil(t, err)
+	if user := doc.Components.Schemas["User"]; assert.NotNil(t, user) {
+		assert.Contains(t, user.Properties, "name")
+		assert.Len(t, user.Properties, 4)
+	}
+}
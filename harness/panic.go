@@ -0,0 +1,111 @@
+package harness
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+// Parses the output of the error if the app panicked. The frames in the application code are
+// mapped to their source lines, and the first one of the panicking goroutine is shown as the
+// source of the error.
+func panicError(err *utils.SourceError, paths *model.RevelContainer) {
+	trace := utils.ParsePanic(err.Stack)
+	if trace == nil {
+		return
+	}
+	err.Panic = trace
+	err.Title = "App panicked: " + strings.SplitN(trace.Message, "\n", 2)[0]
+
+	errorLink := paths.Config.StringDefault("error.link", "")
+	codeDirs := append([]string{paths.BasePath}, paths.ModuleWatchPaths()...)
+	generated := filepath.Join(paths.AppPath, "tmp") + string(filepath.Separator)
+	sources := map[string][]string{}
+	for i, goroutine := range trace.Goroutines {
+		for _, frame := range goroutine.Frames {
+			file := filepath.FromSlash(frame.File)
+			if strings.HasPrefix(file, generated) || !inDirs(file, codeDirs) {
+				continue
+			}
+			frame.App = true
+			if relative, err := filepath.Rel(paths.BasePath, file); err == nil && !strings.HasPrefix(relative, "..") {
+				frame.Path = relative
+			}
+			if errorLink != "" {
+				frame.Link = utils.ErrorLinkURL(errorLink, frame.Path, file, frame.Line, 0)
+			}
+
+			lines, found := sources[file]
+			if !found {
+				lines, _ = utils.ReadLines(file)
+				sources[file] = lines
+			}
+			if frame.Line > 0 && frame.Line <= len(lines) {
+				frame.Source = strings.TrimSpace(lines[frame.Line-1])
+			}
+			if i == 0 && err.Path == "" && lines != nil {
+				err.Path, err.AbsPath, err.Line, err.SourceLines = frame.Path, file, frame.Line, lines
+				if errorLink != "" {
+					err.SetLink(errorLink)
+				}
+			}
+		}
+	}
+}
+
+// Returns true if the file is in one of the folders.
+func inDirs(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		if relative, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(relative, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds the goroutines of the panic to the error page, before the end of its body.
+func addPanicFrames(page []byte, trace *utils.PanicTrace) []byte {
+	frames := &bytes.Buffer{}
+	if err := panicTemplate.Execute(frames, trace); err != nil {
+		utils.Logger.Error("Failed to render the panic", "error", err)
+		return page
+	}
+	end := bytes.LastIndex(page, []byte("</body>"))
+	if end < 0 {
+		return append(page, frames.Bytes()...)
+	}
+	return append(page[:end], append(frames.Bytes(), page[end:]...)...)
+}
+
+// The goroutines of a panic on the error page, the frames link to the editor with error.link.
+var panicTemplate = template.Must(template.New("panic").Funcs(template.FuncMap{
+	// The link is configured by the developer, e.g. with the vscode:// scheme
+	"link": func(link string) template.URL { return template.URL(link) },
+}).Parse(`
+<style type="text/css">
+#panic table { border-collapse: collapse; font-family: monospace; font-size: 14px; }
+#panic td { padding: 2px 10px 2px 0; vertical-align: top; color: #888; }
+#panic tr.app td { color: #333; }
+#panic tr.source td { color: #c00; padding-left: 20px; }
+#panic h3 { font-weight: normal; font-size: 16px; }
+</style>
+<div id="panic" class="block">
+	<h2>Panic: {{.Message}}</h2>
+	{{range .Goroutines}}
+	<h3>goroutine {{.ID}} [{{.State}}]</h3>
+	<table>
+		{{range .Frames}}
+		<tr{{if .App}} class="app"{{end}}>
+			<td>{{.Function}}</td>
+			<td>{{if .Link}}<a href="{{link .Link}}">{{.Path}}:{{.Line}}</a>{{else}}{{.Path}}:{{.Line}}{{end}}</td>
+		</tr>
+		{{if .Source}}<tr class="source"><td colspan="2">{{.Source}}</td></tr>{{end}}
+		{{end}}
+	</table>
+	{{end}}
+</div>
+`))
@@ -0,0 +1,237 @@
+package harness
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template/parse"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+var (
+	templateErrorRegex = regexp.MustCompile(`^template: .*?:(\d+):(?:\d+:)? (.*)$`)
+	undefinedFuncRegex = regexp.MustCompile(`function "([^"]+)" not defined`)
+)
+
+// CheckTemplates parses the Go templates of the application views and the modules, as the
+// template loader of Revel does, so a syntax error fails the build instead of the page which
+// renders it. The functions of the templates are only known when the app runs, so any function
+// is accepted. References to templates which do not exist are logged as warnings.
+func CheckTemplates(paths *model.RevelContainer) error {
+	if !templateEngineUsed(paths) {
+		return nil
+	}
+	var delims []string
+	if delimiters := paths.Config.StringDefault("template.go.delimiters", ""); delimiters != "" {
+		delims = strings.Split(delimiters, " ")
+	}
+
+	root := template.New("__root__")
+	files := map[string]string{} // The file of each template
+	dirs := append([]string{paths.ViewsPath}, paths.TemplatePaths...)
+	if paths.RevelPath != "" {
+		dirs = append(dirs, filepath.Join(paths.RevelPath, "templates"))
+	}
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				if path != dir && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			name := filepath.ToSlash(path[len(dir)+1:])
+			if _, found := files[name]; found || strings.HasPrefix(info.Name(), ".") {
+				return nil
+			}
+			files[name] = path
+			if dir == paths.ViewsPath && len(delims) == 2 {
+				root.Delims(delims[0], delims[1])
+			} else {
+				root.Delims("", "")
+			}
+			return parseTemplate(paths, root, name, path)
+		})
+		if sourceError, ok := err.(*utils.SourceError); ok {
+			return sourceError
+		} else if err != nil {
+			return utils.NewBuildIfError(err, "Failed to read the templates", "path", dir)
+		}
+	}
+
+	for _, tmpl := range root.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		templateReferences(tmpl.Tree.Root, func(node *parse.TemplateNode) {
+			if root.Lookup(node.Name) == nil {
+				location, _ := tmpl.Tree.ErrorContext(node)
+				utils.Logger.Warn("Template references a template which does not exist", "template", node.Name, "location", location)
+			}
+		})
+	}
+	return nil
+}
+
+// Checks the templates when the views change, the app itself is not rebuilt for them as Revel
+// reloads the templates in dev mode. It implements watcher.DiscerningListener.
+type templateListener struct {
+	paths *model.RevelContainer
+	mutex sync.Mutex
+	err   *utils.SourceError // The error of the last check
+}
+
+// Returns the folders of the templates which exist, nil if the Go template engine is not used.
+func templateDirs(paths *model.RevelContainer) (dirs []string) {
+	if !templateEngineUsed(paths) {
+		return nil
+	}
+	for _, dir := range append([]string{paths.ViewsPath}, paths.TemplatePaths...) {
+		if utils.DirExists(dir) && !utils.ContainsString(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return
+}
+
+// Refresh checks the templates, the error is printed and kept until the next check. It is not
+// returned to the watcher, which would rebuild the app on the next request once it is fixed.
+func (l *templateListener) Refresh() *utils.SourceError {
+	var sourceError *utils.SourceError
+	if err := CheckTemplates(l.paths); err != nil {
+		var ok bool
+		if sourceError, ok = err.(*utils.SourceError); !ok {
+			sourceError = &utils.SourceError{Title: "Template Compilation Error", Description: err.Error()}
+		}
+		fmt.Println(sourceError.TerminalString())
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.err = sourceError
+	return nil
+}
+
+// Err returns the error of the last check, nil if the templates are not watched.
+func (l *templateListener) Err() *utils.SourceError {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.err
+}
+
+// WatchDir returns false for the hidden folders, which the check skips.
+func (l *templateListener) WatchDir(info os.FileInfo) bool {
+	return !strings.HasPrefix(info.Name(), ".")
+}
+
+// WatchFile returns true for every file, as a template may have any extension.
+func (l *templateListener) WatchFile(filename string) bool {
+	return true
+}
+
+// Returns true if the Go template engine is one of the template.engines.
+func templateEngineUsed(paths *model.RevelContainer) bool {
+	for _, engine := range strings.Split(paths.Config.StringDefault("template.engines", "go"), ",") {
+		if strings.TrimSpace(strings.ToLower(engine)) == "go" {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses the template file into the set, unless it is for another engine. A parse error is
+// returned as the error of the source.
+func parseTemplate(paths *model.RevelContainer, root *template.Template, name, path string) error {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// The engine is selected by a "#! engine" line, or the name, e.g. "index.pongo2.html"
+	offset := 0
+	if line, _, err := bufio.NewReader(bytes.NewReader(source)).ReadLine(); err == nil && bytes.HasPrefix(line, []byte("#! ")) {
+		if strings.TrimSpace(string(line[2:])) != "go" {
+			return nil
+		}
+		source, offset = source[len(line)+1:], 1
+	} else if bits := strings.Split(filepath.Base(path), "."); len(bits) > 2 && bits[len(bits)-2] != "go" {
+		return nil
+	}
+
+	for stubbed := map[string]bool{}; ; {
+		_, err = root.New(name).Parse(string(source))
+		if err == nil {
+			return nil
+		}
+		match := undefinedFuncRegex.FindStringSubmatch(err.Error())
+		if match == nil || stubbed[match[1]] {
+			return newTemplateError(paths, name, path, offset, err)
+		}
+		stubbed[match[1]] = true
+		root.Funcs(template.FuncMap{match[1]: func(...interface{}) interface{} { return nil }})
+	}
+}
+
+// Returns the source error of a template parse error.
+func newTemplateError(paths *model.RevelContainer, name, path string, offset int, err error) *utils.SourceError {
+	templateError := &utils.SourceError{
+		SourceType:  "template",
+		Title:       "Template Compilation Error",
+		Path:        name,
+		AbsPath:     path,
+		Description: err.Error(),
+	}
+	if match := templateErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+		templateError.Line, _ = strconv.Atoi(match[1])
+		templateError.Line += offset
+		templateError.Description = match[2]
+	}
+	if errorLink := paths.Config.StringDefault("error.link", ""); errorLink != "" {
+		templateError.SetLink(errorLink)
+	}
+	if lines, err := utils.ReadLines(path); err == nil {
+		templateError.SourceLines = lines
+	}
+	return templateError
+}
+
+// Calls the function for every {{template}} in the tree of the node.
+func templateReferences(node parse.Node, found func(*parse.TemplateNode)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node != nil {
+			for _, child := range node.Nodes {
+				templateReferences(child, found)
+			}
+		}
+	case *parse.IfNode:
+		templateReferences(node.List, found)
+		templateReferences(node.ElseList, found)
+	case *parse.RangeNode:
+		templateReferences(node.List, found)
+		templateReferences(node.ElseList, found)
+	case *parse.WithNode:
+		templateReferences(node.List, found)
+		templateReferences(node.ElseList, found)
+	case *parse.TemplateNode:
+		found(node)
+	}
+}
@@ -0,0 +1,190 @@
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/revel/cmd/logger"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates an application with the views.
+func newTemplateApp(t *testing.T, views map[string]string) *model.RevelContainer {
+	basePath, err := ioutil.TempDir("", "revel-templates")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(basePath) })
+	paths := &model.RevelContainer{
+		BasePath:  basePath,
+		AppPath:   filepath.Join(basePath, "app"),
+		ViewsPath: filepath.Join(basePath, "app", "views"),
+		Config:    config.NewContext(),
+	}
+	for name, source := range views {
+		writeView(t, paths, name, source)
+	}
+	return paths
+}
+
+func writeView(t *testing.T, paths *model.RevelContainer, name, source string) {
+	path := filepath.Join(paths.ViewsPath, filepath.FromSlash(name))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(source), 0644))
+}
+
+// Returns the source error of the template check.
+func checkTemplates(t *testing.T, paths *model.RevelContainer) *utils.SourceError {
+	err := CheckTemplates(paths)
+	if err == nil {
+		return nil
+	}
+	sourceError, ok := err.(*utils.SourceError)
+	assert.True(t, ok, "The error should be a source error: %v", err)
+	return sourceError
+}
+
+func TestCheckTemplatesError(t *testing.T) {
+	paths := newTemplateApp(t, map[string]string{
+		"App/Index.html": "<h1>\n  {{.title}}\n  {{if .user}}{{.user.Name}\n</h1>\n",
+		"header.html":    "<head></head>\n",
+	})
+	paths.Config.SetOption("error.link", "file")
+	err := checkTemplates(t, paths)
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Equal(t, "Template Compilation Error", err.Title)
+	assert.Equal(t, "App/Index.html", err.Path)
+	assert.Equal(t, filepath.Join(paths.ViewsPath, "App", "Index.html"), err.AbsPath)
+	assert.Equal(t, 3, err.Line)
+	assert.Contains(t, err.Description, "bad character")
+	assert.Equal(t, "  {{if .user}}{{.user.Name}", err.SourceLines[2])
+	assert.Equal(t, "file://"+filepath.ToSlash(err.AbsPath), err.URL)
+
+	// The templates of another engine are not checked
+	paths.Config.SetOption("template.engines", "pongo2")
+	assert.Nil(t, checkTemplates(t, paths))
+}
+
+func TestCheckTemplatesEngine(t *testing.T) {
+	paths := newTemplateApp(t, map[string]string{
+		"pongo2.html":       "#! pongo2\n{{ if }}\n",
+		"index.pongo2.html": "{{ if }}\n",
+	})
+	assert.Nil(t, checkTemplates(t, paths), "The templates of pongo2 should be skipped")
+
+	// The line of the engine is not part of the template, the error is on the line of the file
+	writeView(t, paths, "go.html", "#! go\n<p>\n{{ end }}\n")
+	err := checkTemplates(t, paths)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "go.html", err.Path)
+		assert.Equal(t, 3, err.Line)
+		assert.Contains(t, err.Description, "unexpected {{end}}")
+	}
+}
+
+func TestCheckTemplatesFuncs(t *testing.T) {
+	paths := newTemplateApp(t, map[string]string{
+		"index.html": `{{msg . "greeting"}} {{url "App.Index"}} {{if eq (msg . "a") "b"}}{{end}}` + "\n",
+	})
+	assert.Nil(t, checkTemplates(t, paths), "The functions of the app are not known, they should be accepted")
+
+	// The syntax errors are still found in the templates with unknown functions
+	writeView(t, paths, "index.html", "\n{{msg . \"greeting\"}}{{end}}\n")
+	err := checkTemplates(t, paths)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, 2, err.Line)
+	}
+}
+
+func TestCheckTemplatesMissing(t *testing.T) {
+	var mutex sync.Mutex
+	var warnings []logger.ContextMap
+	utils.Logger.SetHandler(logger.FuncHandler(func(r *logger.Record) error {
+		if r.Level == logger.LvlWarn {
+			mutex.Lock()
+			warnings = append(warnings, r.Context)
+			mutex.Unlock()
+		}
+		return nil
+	}))
+	defer utils.Logger.SetHandler(logger.StreamHandler(os.Stderr, logger.TerminalFormatHandler(true, true)))
+
+	paths := newTemplateApp(t, map[string]string{
+		"App/Index.html": `{{template "header.html" .}}{{if .}}{{template "footer.html" .}}{{end}}` + "\n",
+		"header.html":    "<head></head>\n",
+	})
+	assert.Nil(t, checkTemplates(t, paths), "A missing template should not fail the check")
+	mutex.Lock()
+	defer mutex.Unlock()
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "footer.html", warnings[0]["template"])
+		assert.Contains(t, warnings[0]["location"], "App/Index.html:1")
+	}
+}
+
+// Test that the views are watched and checked by the template listener.
+func TestTemplateListener(t *testing.T) {
+	paths := newTemplateApp(t, map[string]string{"index.html": "{{.title}}\n"})
+	paths.TemplatePaths = []string{paths.ViewsPath, filepath.Join(paths.BasePath, "missing")}
+	assert.Equal(t, []string{paths.ViewsPath}, templateDirs(paths))
+
+	// The error is kept by the listener until the next check, the watcher does not see it
+	listener := &templateListener{paths: paths}
+	assert.Nil(t, listener.Refresh())
+	assert.Nil(t, listener.Err())
+	writeView(t, paths, "index.html", "{{.title}\n")
+	assert.Nil(t, listener.Refresh())
+	if err := listener.Err(); assert.NotNil(t, err) {
+		assert.Equal(t, "index.html", err.Path)
+	}
+	writeView(t, paths, "index.html", "{{.title}}\n")
+	assert.Nil(t, listener.Refresh())
+	assert.Nil(t, listener.Err())
+
+	assert.True(t, listener.WatchFile(filepath.Join(paths.ViewsPath, "index.txt")))
+	for _, dir := range []struct {
+		name    string
+		watched bool
+	}{{"App", true}, {".git", false}} {
+		info, _ := os.Stat(paths.ViewsPath)
+		assert.Equal(t, dir.watched, listener.WatchDir(namedFileInfo{info, dir.name}), dir.name)
+	}
+
+	paths.Config.SetOption("template.engines", "pongo2")
+	assert.Nil(t, templateDirs(paths), "The views of another engine should not be watched")
+}
+
+// Test that the harness shows the error of the templates instead of proxying the request.
+func TestHarnessTemplateError(t *testing.T) {
+	h := newTestHarness(t, "app", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "app")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "app", w.Body.String(), "The views are not watched")
+
+	h.templates = &templateListener{paths: newTemplateApp(t, map[string]string{"index.html": "{{.title}\n"})}
+	h.templates.Refresh()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, w.Body.String(), "Template Compilation Error")
+}
+
+// A file info with another name.
+type namedFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i namedFileInfo) Name() string {
+	return i.name
+}
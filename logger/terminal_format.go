@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/mattn/go-isatty"
 )
 
 const (
@@ -23,6 +26,21 @@ var levelString = map[LogLevel]string{
 	LvlInfo:  "INFO", LvlWarn: "WARN", LvlError: "ERROR", LvlCrit: "CRIT",
 }
 
+// TerminalNoColor returns true if the output to the file should not be coloured, which is when it
+// is not a terminal or the NO_COLOR environment variable is set (https://no-color.org).
+func TerminalNoColor(file *os.File) bool {
+	return os.Getenv("NO_COLOR") != "" || !(isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd()))
+}
+
+// Colorize wraps the text in the escape sequence of the colour, e.g. "31" for red or "1;31" for
+// bold red, unless noColor is set.
+func Colorize(text, color string, noColor bool) string {
+	if noColor || color == "" {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
+
 // Outputs to the terminal in a format like below
 // INFO  09:11:32 server-engine.go:169: Request Stats.
 func TerminalFormatHandler(noColor bool, smallDate bool) LogFormat {
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/revel/cmd/utils"
+)
+
+const (
+	ErrInvalidAppRoute Error = "invalid application, expected path, path=/prefix or path=host"
+	ErrDuplicateRoute  Error = "the route is used by more than one application"
+)
+
+// AppRoute describes one of the applications run behind the harness proxy, the requests are
+// routed to it by host name or path prefix.
+type AppRoute struct {
+	Path   string // The folder or import path of the application
+	Prefix string // The path prefix routed to the application, removed before forwarding
+	Host   string // The host name routed to the application
+}
+
+// ParseAppRoute parses path, path=/prefix or path=host. An application without a route is
+// routed by the name of its folder, e.g. "./api" is served under "/api".
+func ParseAppRoute(value string) (route AppRoute, err error) {
+	route.Path = value
+	if index := strings.LastIndex(value, "="); index >= 0 {
+		route.Path = value[:index]
+		if target := value[index+1:]; strings.HasPrefix(target, "/") {
+			route.Prefix = target
+		} else {
+			route.Host = strings.ToLower(target)
+		}
+	}
+	if route.Path == "" || (route.Prefix == "" && route.Host == "" && strings.HasSuffix(value, "=")) {
+		return route, fmt.Errorf("%w: %s", ErrInvalidAppRoute, value)
+	}
+	if route.Prefix == "" && route.Host == "" {
+		route.Prefix = "/" + filepath.Base(filepath.Clean(route.Path))
+	}
+	if route.Prefix != "/" {
+		route.Prefix = strings.TrimRight(route.Prefix, "/")
+	}
+	return
+}
+
+// Matches returns true if the request for the host and path is routed to the application.
+func (r AppRoute) Matches(host, path string) bool {
+	if r.Host != "" {
+		if index := strings.LastIndex(host, ":"); index >= 0 && !strings.HasSuffix(host, "]") {
+			host = host[:index]
+		}
+		return strings.EqualFold(host, r.Host)
+	}
+	return r.Prefix == "/" || path == r.Prefix || strings.HasPrefix(path, r.Prefix+"/")
+}
+
+// String returns the route as shown to the user.
+func (r AppRoute) String() string {
+	if r.Host != "" {
+		return "http://" + r.Host + "/"
+	}
+	return r.Prefix
+}
+
+// ParseAppRoutes parses the --app values, two applications cannot use the same route.
+func ParseAppRoutes(values []string) (routes []AppRoute, err error) {
+	used := map[string]string{}
+	for _, value := range values {
+		route, err := ParseAppRoute(value)
+		if err != nil {
+			return nil, err
+		}
+		if path, found := used[route.String()]; found {
+			return nil, fmt.Errorf("%w: %s (%s, %s)", ErrDuplicateRoute, route, path, route.Path)
+		}
+		used[route.String()] = route.Path
+		routes = append(routes, route)
+	}
+	return
+}
+
+// AppConfig returns a copy of the command configuration for the application of the route, the
+// import path and application folder are determined as they are for a single application. A
+// relative path is resolved from the working directory, or else from the folder of the project
+// configuration which listed it.
+func (c *CommandConfig) AppConfig(route AppRoute) (*CommandConfig, error) {
+	app := *c
+	app.Run.Apps = nil
+	app.Run.ImportPath = route.Path
+	if !filepath.IsAbs(route.Path) && !utils.DirExists(route.Path) && c.ProjectConfig != nil {
+		if dir := filepath.Join(filepath.Dir(c.ProjectConfig.Path), route.Path); utils.DirExists(dir) {
+			app.Run.ImportPath = dir
+		}
+	}
+	if utils.DirExists(app.Run.ImportPath) {
+		app.Run.ImportPath, _ = filepath.Abs(app.Run.ImportPath)
+	}
+	app.InitPackageResolver()
+	if err := app.UpdateImportPath(); err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
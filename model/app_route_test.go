@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the application routes are parsed and matched by host name or path prefix.
+func TestAppRoutes(t *testing.T) {
+	routes, err := model.ParseAppRoutes([]string{"./api=/api/", "../web", "./admin=Admin.localhost", "./site=/"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []model.AppRoute{
+		{Path: "./api", Prefix: "/api"},
+		{Path: "../web", Prefix: "/web"},
+		{Path: "./admin", Host: "admin.localhost"},
+		{Path: "./site", Prefix: "/"},
+	}, routes)
+
+	assert.True(t, routes[0].Matches("localhost:9000", "/api"))
+	assert.True(t, routes[0].Matches("localhost:9000", "/api/users"))
+	assert.False(t, routes[0].Matches("localhost:9000", "/apiary"))
+	assert.True(t, routes[2].Matches("admin.localhost:9000", "/"))
+	assert.False(t, routes[2].Matches("localhost:9000", "/"))
+	assert.True(t, routes[3].Matches("localhost:9000", "/anything"))
+
+	_, err = model.ParseAppRoutes([]string{"./api="})
+	assert.True(t, errors.Is(err, model.ErrInvalidAppRoute))
+	_, err = model.ParseAppRoutes([]string{"./api", "./other/api"})
+	assert.True(t, errors.Is(err, model.ErrDuplicateRoute), "Both are served under /api")
+}
@@ -0,0 +1,10 @@
+package command
+
+type (
+	Config struct {
+		ImportCommand
+		Show ConfigShow `command:"show" description:"Print the configuration merged from the project configuration and the command line"`
+	}
+
+	ConfigShow struct{}
+)
@@ -0,0 +1,8 @@
+package command
+
+type (
+	Doctor struct {
+		ImportCommand
+		Mode string `short:"m" long:"run-mode" description:"The mode to check the configuration of"`
+	}
+)
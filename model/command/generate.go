@@ -0,0 +1,26 @@
+package command
+
+type (
+	Generate struct {
+		ImportCommand
+		Mode       string             `short:"m" long:"run-mode" description:"The mode to read the configuration in"`
+		DryRun     bool               `short:"n" long:"dry-run" description:"Print the files that would be generated without writing them"`
+		Generator  string             // The name of the generator selected
+		Args       []string           // The arguments passed to the generator
+		Client     GenerateClient     `command:"client" description:"Generate a Go client package for calling the application actions"`
+		Controller GenerateController `command:"controller" description:"Generate a controller with CRUD actions, routes and views. Usage: controller <name>"`
+		Model      GenerateModel      `command:"model" description:"Generate a model struct. Usage: model <name> [field:type ...]"`
+		Test       GenerateTest       `command:"test" description:"Generate a test suite under tests. Usage: test <name>"`
+	}
+
+	GenerateClient struct {
+		TargetPath  string `short:"t" long:"target-path" description:"Path to the generated file, defaults to client/client.go in the application folder" required:"false"`
+		PackageName string `short:"p" long:"package" description:"The package name of the generated client, defaults to the name of the target folder"`
+	}
+
+	GenerateController struct{}
+
+	GenerateModel struct{}
+
+	GenerateTest struct{}
+)
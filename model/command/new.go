@@ -3,10 +3,13 @@ package command
 type (
 	New struct {
 		ImportCommand
-		SkeletonPath string `short:"s" long:"skeleton" description:"Path to skeleton folder (Must exist on GO PATH)" required:"false"`
-		Package      string `short:"p" long:"package" description:"The package name, this becomes the repfix to the app name, if defined vendored is set to true" required:"false"`
-		NotVendored  bool   `long:"no-vendor" description:"True if project should not be configured with a go.mod, this requires you to have the project on the GOPATH, this is only compatible with go versions v1.12 or older"`
-		Run          bool   `short:"r" long:"run" description:"True if you want to run the application right away"`
-		Callback     func() error
+		SkeletonPath    string   `short:"s" long:"skeleton" description:"Path to skeleton folder, git url (#ref), .tar.gz or .zip archive, or module@version fetched using GOPROXY. Append :sub/folder to select a folder" required:"false"`
+		Package         string   `short:"p" long:"package" description:"The package name, this becomes the repfix to the app name, if defined vendored is set to true" required:"false"`
+		NotVendored     bool     `long:"no-vendor" description:"True if project should not be configured with a go.mod, this requires you to have the project on the GOPATH, this is only compatible with go versions v1.12 or older"`
+		Run             bool     `short:"r" long:"run" description:"True if you want to run the application right away"`
+		Set             []string `long:"set" description:"Set a variable declared by the skeleton manifest, key=value. May be specified multiple times"`
+		Callback        func() error
+		PostCreate      []string // The post create hooks of the skeleton
+		SkeletonTempDir string   // The folder the skeleton was fetched into, removed when done
 	}
 )
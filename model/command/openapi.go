@@ -0,0 +1,10 @@
+package command
+
+type (
+	OpenAPI struct {
+		ImportCommand
+		TargetPath string `short:"t" long:"target-path" description:"Path to the generated file, defaults to openapi.yaml in the application folder" required:"false"`
+		Mode       string `short:"m" long:"run-mode" description:"The mode to read the configuration in"`
+		Format     string `short:"f" long:"format" description:"The output format, yaml or json. Defaults to the extension of the target path" choice:"yaml" choice:"json"`
+	}
+)
@@ -0,0 +1,12 @@
+package command
+
+type (
+	Profile struct {
+		ImportCommand
+		Mode    string `short:"m" long:"run-mode" description:"The mode the application runs in, to read the proxy address from the app.conf"`
+		Seconds int    `long:"seconds" default:"30" description:"The duration of the cpu profile and the trace"`
+		Output  string `short:"o" long:"output" description:"The file the profile is written to, by default <profile>-<time>.pprof in the working folder"`
+		URL     string `long:"url" description:"The URL of the harness proxy, by default read from the app.conf"`
+		Name    string // The profile to capture
+	}
+)
@@ -3,8 +3,16 @@ package command
 type (
 	Run struct {
 		ImportCommand
-		Mode    string `short:"m" long:"run-mode" description:"The mode to run the application in"`
-		Port    int    `short:"p" long:"port" default:"-1" description:"The port to listen" `
-		NoProxy bool   `short:"n" long:"no-proxy" description:"True if proxy server should not be started. This will only update the main and routes files on change"`
+		Mode      string   `short:"m" long:"run-mode" description:"The mode to run the application in"`
+		Port      int      `short:"p" long:"port" default:"-1" description:"The port to listen" `
+		NoProxy   bool     `short:"n" long:"no-proxy" description:"True if proxy server should not be started. This will only update the main and routes files on change"`
+		Apps      []string `long:"app" description:"An application to run behind the proxy, as path, path=/prefix or path=host. The prefix is removed before the request is forwarded. May be specified multiple times, or listed under run.app in the revel.yaml"`
+		Delve     bool     `long:"delve" description:"Run the application, built without optimizations, under a headless Delve debugger, which must be installed. Formerly --debug"`
+		DelveAddr string   `long:"delve-addr" default:"localhost:2345" description:"The address the headless debugger listens on, it is the same after every rebuild so the IDE can reconnect. With several applications each one listens on the next port"`
+		Race      bool     `long:"race" description:"Build the application with the race detector"`
+		Cover     bool     `long:"cover" description:"Build the application with coverage instrumentation, the data is written to the GOCOVERDIR folder, or test-results/coverage, on exit"`
+		Pprof     bool     `long:"pprof" description:"Serve the net/http/pprof endpoints of the application on a side port, the proxy forwards /@harness/pprof/ to them. The profiles are captured with revel profile"`
+		Inspect   bool     `long:"inspect" description:"Record the recent requests and responses passing through the proxy, they are shown at /@harness/requests, exported as HAR at /@harness/requests.har and may be replayed"`
+		HTTPS     bool     `long:"https" description:"Serve the proxy over HTTPS with a certificate of a local CA generated in the user config folder, the application keeps serving HTTP"`
 	}
 )
@@ -3,7 +3,10 @@ package command
 type (
 	Test struct {
 		ImportCommand
-		Mode     string `short:"m" long:"run-mode" description:"The mode to run the application in"`
-		Function string `short:"f" long:"suite-function" description:"The suite.function"`
+		Mode     string   `short:"m" long:"run-mode" description:"The mode to run the application in"`
+		Function string   `short:"f" long:"suite-function" description:"The suite.function"`
+		Reports  []string `short:"r" long:"report" default:"html" choice:"html" choice:"json" choice:"junit" description:"The report formats written to the test-results folder. May be specified multiple times"`
+		Race     bool     `long:"race" description:"Build the application with the race detector"`
+		Cover    bool     `long:"cover" description:"Measure the coverage of the application while the tests run, the report is written to the test-results folder"`
 	}
 )
@@ -26,11 +26,17 @@ const (
 	CLEAN
 	TEST
 	VERSION
+	OPENAPI
+	GENERATE
+	DOCTOR
+	CONFIG
+	PROFILE
 )
 
 const (
 	ErrImportInvalid  Error = "invalid import path, working dir is in GOPATH root"
 	ErrUnableToImport Error = "unable to determine import path from"
+	ErrOffline        Error = "offline mode, missing packages are not fetched"
 )
 
 type (
@@ -55,6 +61,11 @@ type (
 		PackageResolver   func(pkgName string) error //  a package resolver for the config
 		BuildFlags        []string                   `short:"X" long:"build-flags" description:"These flags will be used when building the application. May be specified multiple times, only applicable for Build, Run, Package, Test commands"`
 		GoModFlags        []string                   `long:"gomod-flags" description:"These flags will execute go mod commands for each flag, this happens during the build process"`
+		Offline           bool                       `long:"offline" env:"REVEL_OFFLINE" description:"Never fetch packages or update the go.mod and go.sum, missing packages fail the build. May be set with REVEL_OFFLINE=true"`
+		ResolverName      string                     `long:"resolver" choice:"tidy" choice:"get" choice:"work" choice:"none" choice:"deny" description:"How missing packages are fetched: tidy (the default for modules), get (the default for GOPATH applications), work (the default in a go.work workspace), none or deny"`
+		PinnedVersions    []string                   `long:"pin" description:"The version the get and work resolvers fetch for a module, module@version. May be specified multiple times"`
+		Ini               string                     `long:"ini" no-ini:"true" description:"Read the default options from this ini file, the command line overrides them"`
+		ProjectConfig     *ProjectConfig             // The project configuration (.revel.toml or revel.yaml), if found
 		New               command.New                `command:"new"`
 		Build             command.Build              `command:"build"`
 		Run               command.Run                `command:"run"`
@@ -62,6 +73,11 @@ type (
 		Clean             command.Clean              `command:"clean"`
 		Test              command.Test               `command:"test"`
 		Version           command.Version            `command:"version"`
+		OpenAPI           command.OpenAPI            `command:"openapi"`
+		Generate          command.Generate           `command:"generate" alias:"gen"`
+		Doctor            command.Doctor             `command:"doctor"`
+		Config            command.Config             `command:"config"`
+		Profile           command.Profile            `command:"profile"`
 	}
 )
 
@@ -90,6 +106,24 @@ func (c *CommandConfig) UpdateImportPath() error {
 	case VERSION:
 		importPath = c.Version.ImportPath
 		required = false
+	case OPENAPI:
+		importPath = c.OpenAPI.ImportPath
+		c.Vendored = utils.Exists(filepath.Join(importPath, "go.mod"))
+	case GENERATE:
+		importPath = c.Generate.ImportPath
+		c.Vendored = utils.Exists(filepath.Join(importPath, "go.mod"))
+	case DOCTOR:
+		importPath = c.Doctor.ImportPath
+		c.Vendored = utils.Exists(filepath.Join(importPath, "go.mod"))
+		// The doctor checks the versions itself, so it can report on them
+		required = false
+	case CONFIG:
+		importPath = c.Config.ImportPath
+		required = false
+	case PROFILE:
+		importPath = c.Profile.ImportPath
+		// The profile is taken from a running application, only its app.conf is read
+		required = false
 	}
 
 	if len(importPath) == 0 || filepath.IsAbs(importPath) || importPath[0] == '.' {
@@ -128,7 +162,7 @@ func (c *CommandConfig) UpdateImportPath() error {
 	c.ImportPath = importPath
 	// We need the source root determined at this point to check the setversions
 	if err := c.initAppFolder(); err != nil {
-		utils.Logger.Error("Error initing app folder", "error", err)
+		return err
 	}
 
 	utils.Logger.Info("Returned import path", "path", importPath)
@@ -179,6 +213,18 @@ func (c *CommandConfig) initAppFolder() (err error) {
 
 	utils.Logger.Info("Determined app folder to be", "appfolder", appFolder, "working", wd, "importPath", c.ImportPath)
 
+	// In a go.work workspace the import path may be the module of one of the used folders
+	if workspace, err := FindWorkspace(appFolder); err != nil {
+		return err
+	} else if workspace != nil {
+		utils.Logger.Info("Found go.work workspace", "path", workspace.Path)
+		if dir, found := workspace.Modules[c.ImportPath]; found && !utils.Exists(filepath.Join(appFolder, "go.mod")) {
+			appFolder = dir
+		}
+		// The go command refuses -mod=mod in workspace mode
+		c.setReadonlyGoFlags()
+	}
+
 	// Use app folder to read the go.mod if it exists and extract the package information
 	goModFile := filepath.Join(appFolder, "go.mod")
 	utils.Logger.Info("Checking gomod, extracting from file", "path", goModFile, "exists", utils.Exists(goModFile))
@@ -232,33 +278,119 @@ func (c *CommandConfig) initAppFolder() (err error) {
 	return nil
 }
 
+// Instrumentation returns the race detector and coverage options of the run and test commands.
+func (c *CommandConfig) Instrumentation() (race, cover bool) {
+	switch c.Index {
+	case RUN:
+		return c.Run.Race, c.Run.Cover
+	case TEST:
+		return c.Test.Race, c.Test.Cover
+	}
+	return
+}
+
 // Used to initialize the package resolver.
 func (c *CommandConfig) InitPackageResolver() {
 	c.initGoPaths()
-	utils.Logger.Info("InitPackageResolver", "useVendor", c.Vendored, "path", c.AppPath)
+	utils.Logger.Info("InitPackageResolver", "useVendor", c.Vendored, "path", c.AppPath, "offline", c.Offline)
 
-	// This should get called when needed
+	if c.Offline {
+		c.initOffline()
+	}
+
+	// The resolver is created for each request, as the application path is not known yet
 	c.PackageResolver = func(pkgName string) error {
-		utils.Logger.Info("Request for package ", "package", pkgName, "use vendor", c.Vendored)
-		var getCmd *exec.Cmd
-		print("Downloading related packages ...")
-		if c.Vendored {
-			getCmd = exec.Command(c.GoCmd, "mod", "tidy", "-v")
-		} else {
-			utils.Logger.Info("No vendor folder detected, not using dependency manager to import package", "package", pkgName)
-			getCmd = exec.Command(c.GoCmd, "get", "-u", pkgName)
+		resolver, err := c.NewResolver()
+		if err != nil {
+			return err
 		}
+		utils.Logger.Info("Request for package", "package", pkgName, "resolver", fmt.Sprintf("%T", resolver))
+		return resolver.Resolve(pkgName)
+	}
+}
 
-		utils.CmdInit(getCmd, !c.Vendored, c.AppPath)
-		utils.Logger.Info("Go get command ", "exec", getCmd.Path, "dir", getCmd.Dir, "args", getCmd.Args, "env", getCmd.Env, "package", pkgName)
-		output, err := getCmd.CombinedOutput()
-		if err != nil {
-			utils.Logger.Error("Failed to import package", "error", err, "gopath", build.Default.GOPATH, "GO-ROOT", build.Default.GOROOT, "output", string(output))
+// NewResolver returns the resolver selected by --resolver. By default modules are tidied, or
+// synced in a go.work workspace, and GOPATH applications use go get.
+func (c *CommandConfig) NewResolver() (Resolver, error) {
+	if c.Offline {
+		return &DenyResolver{Offline: true}, nil
+	}
+	versions, err := ParsePinnedVersions(c.PinnedVersions)
+	if err != nil {
+		return nil, err
+	}
+	get := GetResolver{GoCmd: c.GoCmd, Dir: c.AppPath, Versions: versions, GoPath: !c.Vendored}
+
+	name := c.ResolverName
+	if name == "" {
+		switch {
+		case !c.Vendored:
+			name = ResolverGet
+		case c.inWorkspace():
+			name = ResolverWork
+		default:
+			name = ResolverTidy
 		}
-		println(" completed.")
+	}
+	switch name {
+	case ResolverTidy:
+		return &TidyResolver{GoCmd: c.GoCmd, Dir: c.AppPath}, nil
+	case ResolverGet:
+		return &get, nil
+	case ResolverWork:
+		return &WorkResolver{GetResolver: get}, nil
+	case ResolverNone:
+		return &NoopResolver{}, nil
+	case ResolverDeny:
+		return &DenyResolver{}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownResolver, name)
+}
 
-		return nil
+// Returns true if the application is part of a go.work workspace.
+func (c *CommandConfig) inWorkspace() bool {
+	cmd := exec.Command(c.GoCmd, "env", "GOWORK")
+	cmd.Dir = c.AppPath
+	output, err := cmd.Output()
+	goWork := strings.TrimSpace(string(output))
+	return err == nil && goWork != "" && goWork != "off"
+}
+
+// Stops the go commands from fetching modules or updating the go.mod, the environment is passed
+// on to every go command run by the tool.
+func (c *CommandConfig) initOffline() {
+	_ = os.Setenv("GOPROXY", "off")
+	c.setReadonlyGoFlags()
+}
+
+// Replaces -mod=mod in GOFLAGS with -mod=readonly, or adds it when no -mod flag is set, so the go
+// commands never update the go.mod. A -mod=vendor flag is kept.
+func (c *CommandConfig) setReadonlyGoFlags() {
+	goFlags := os.Getenv("GOFLAGS")
+	if goFlags == "" {
+		if output, err := exec.Command(c.GoCmd, "env", "GOFLAGS").Output(); err == nil {
+			goFlags = strings.TrimSpace(string(output))
+		}
+	}
+	flags, modFlag := strings.Fields(goFlags), false
+	for i, flag := range flags {
+		if strings.HasPrefix(strings.TrimLeft(flag, "-"), "mod=") {
+			modFlag = true
+			if strings.TrimLeft(flag, "-") == "mod=mod" {
+				flags[i] = "-mod=readonly"
+			}
+		}
+	}
+	if !modFlag {
+		flags = append(flags, "-mod=readonly")
 	}
+	_ = os.Setenv("GOFLAGS", strings.Join(flags, " "))
+}
+
+// OfflineError returns the error for packages which cannot be found locally in offline mode.
+func OfflineError(pkgNames ...string) error {
+	return fmt.Errorf("%w: %s (run 'go mod download' with network access, or vendor the dependencies)",
+		ErrOffline, strings.Join(pkgNames, ", "))
 }
 
 // lookup and set Go related variables.
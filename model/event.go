@@ -43,8 +43,35 @@ const (
 
 	// Fired when a panic is caught during the startup process.
 	REVEL_FAILURE
+
+	// Fired by the command line tool before the application is built, the value is the RevelContainer.
+	BUILD_STARTED
+	// Fired after the source of the application is processed, the value is the RevelContainer.
+	SOURCE_PROCESSED
+	// Fired after the application is built, the value is the path of the binary.
+	BUILD_COMPLETED
+	// Fired after revel build wrote the target folder, the value is its path.
+	BUILD_TARGET_CREATED
+	// Fired before revel package builds the application, the value is the RevelContainer.
+	PACKAGE_STARTED
+	// Fired after revel package created the archive, the value is its path.
+	PACKAGE_CREATED
+	// Fired when the application started and listens, the value is its address.
+	APP_STARTED
 )
 
+// EventHooks are the app.conf keys of the shell commands run on the events of the command line
+// tool, e.g. "hook.build.pre = make assets".
+var EventHooks = map[Event]string{
+	BUILD_STARTED:        "hook.build.pre",
+	SOURCE_PROCESSED:     "hook.build.source",
+	BUILD_COMPLETED:      "hook.build.post",
+	BUILD_TARGET_CREATED: "hook.build.target",
+	PACKAGE_STARTED:      "hook.package.pre",
+	PACKAGE_CREATED:      "hook.package.post",
+	APP_STARTED:          "hook.app.start",
+}
+
 var initEventList = []EventHandler{} // Event handler list for receiving events
 
 // Fires system events from revel.
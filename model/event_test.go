@@ -3,6 +3,7 @@ package model_test
 import (
 	"testing"
 
+	"github.com/revel/cmd/model"
 	"github.com/revel/revel"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,3 +23,19 @@ func TestEventHandler(t *testing.T) {
 	revel.StopServer(1)
 	assert.Equal(t, counter, 2, "Expected event handler to have been called")
 }
+
+// Test that the events of the command line tool are dispatched, and each one has a hook.
+func TestBuildEventHandler(t *testing.T) {
+	var received []model.Event
+	model.AddInitEventHandler(func(typeOf model.Event, value interface{}) (responseOf model.EventResponse) {
+		received = append(received, typeOf)
+		return
+	})
+	events := []model.Event{model.BUILD_STARTED, model.SOURCE_PROCESSED, model.BUILD_COMPLETED,
+		model.BUILD_TARGET_CREATED, model.PACKAGE_STARTED, model.PACKAGE_CREATED, model.APP_STARTED}
+	for _, event := range events {
+		model.RaiseEvent(event, nil)
+		assert.NotEmpty(t, model.EventHooks[event], "Expected a hook for the event")
+	}
+	assert.Equal(t, events, received)
+}
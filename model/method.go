@@ -11,6 +11,7 @@ type MethodCall struct {
 // MethodSpec holds the information of one Method.
 type MethodSpec struct {
 	Name        string        // Name of the method, e.g. "Index"
+	Doc         string        // The doc comment of the method, if any
 	Args        []*MethodArg  // Argument descriptors
 	RenderCalls []*MethodCall // Descriptions of Render() invocations from this Method.
 }
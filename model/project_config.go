@@ -0,0 +1,202 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/revel/cmd/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFiles are the names of the project configuration, looked for in the project root.
+var ProjectConfigFiles = []string{".revel.toml", "revel.toml", ".revel.yaml", "revel.yaml", ".revel.yml", "revel.yml"}
+
+const ErrProjectConfigInvalid Error = "invalid value in project configuration"
+
+// ProjectConfig holds the command line defaults read from the project configuration. The keys
+// are the long option names, top level keys are the global options and the tables are the
+// commands, e.g.
+//
+//	build-flags = ["github.com/myorg/myapp/app.Env=dev"]
+//
+//	[run]
+//	port = 9100
+//
+//	[generate.client]
+//	target-path = "client/client.go"
+type ProjectConfig struct {
+	Path     string                         // The path the configuration was read from
+	Globals  map[string][]string            // The global options
+	Sections map[string]map[string][]string // The options of the commands, by the command name (e.g. "generate.client")
+}
+
+// FindProjectConfig looks for the project configuration in the folder and its parents, the search
+// stops at the first folder containing a go.mod. Nil is returned if none was found.
+func FindProjectConfig(path string) (*ProjectConfig, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		for _, name := range ProjectConfigFiles {
+			if configPath := filepath.Join(path, name); utils.Exists(configPath) {
+				return LoadProjectConfig(configPath)
+			}
+		}
+		parent := filepath.Dir(path)
+		if parent == path || utils.Exists(filepath.Join(path, "go.mod")) {
+			return nil, nil
+		}
+		path = parent
+	}
+}
+
+// LoadProjectConfig reads the project configuration from the toml or yaml file.
+func LoadProjectConfig(path string) (config *ProjectConfig, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to read project configuration", "path", path)
+	}
+	values := map[string]interface{}{}
+	if filepath.Ext(path) == ".toml" {
+		err = toml.Unmarshal(content, &values)
+	} else {
+		err = yaml.Unmarshal(content, &values)
+	}
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to parse project configuration", "path", path)
+	}
+
+	config = &ProjectConfig{Path: path, Globals: map[string][]string{}, Sections: map[string]map[string][]string{}}
+	if err = config.add("", values); err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to parse project configuration", "path", path)
+	}
+	return config, nil
+}
+
+// Adds the values of the table, nested tables are added as the sections of sub commands.
+func (p *ProjectConfig) add(section string, table map[string]interface{}) (err error) {
+	for key, value := range table {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err = p.add(strings.TrimPrefix(section+"."+key, "."), nested); err != nil {
+				return
+			}
+			continue
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			list = []interface{}{value}
+		}
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			text, err := projectConfigValue(section, key, item)
+			if err != nil {
+				return err
+			}
+			values = append(values, text)
+		}
+
+		options := p.Globals
+		if section != "" {
+			if options = p.Sections[section]; options == nil {
+				options = map[string][]string{}
+				p.Sections[section] = options
+			}
+		}
+		options[key] = values
+	}
+	return
+}
+
+// Returns the value as a string, only scalar values are allowed.
+func projectConfigValue(section, key string, value interface{}) (string, error) {
+	switch value.(type) {
+	case string, bool, int, int64, float64:
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("%w %s: %v", ErrProjectConfigInvalid, strings.TrimPrefix(section+"."+key, "."), value)
+}
+
+// Ini returns the configuration in the ini format read by the command line parser. The
+// section options which are global options (like build-flags) are used in place of the global
+// value when the section is the command being run, the globals argument returns true for those.
+func (p *ProjectConfig) Ini(command string, global func(key string) bool) string {
+	globals := map[string][]string{}
+	for key, values := range p.Globals {
+		globals[key] = values
+	}
+	sections := map[string]map[string][]string{}
+	for name, options := range p.Sections {
+		sections[name] = map[string][]string{}
+		for key, values := range options {
+			if global(key) {
+				if name == command {
+					globals[key] = values
+				}
+				continue
+			}
+			sections[name][key] = values
+		}
+	}
+
+	b := &strings.Builder{}
+	writeIniOptions(b, globals)
+	for _, name := range sortedKeys(sections) {
+		if len(sections[name]) > 0 {
+			fmt.Fprintf(b, "\n[%s]\n", name)
+			writeIniOptions(b, sections[name])
+		}
+	}
+	return b.String()
+}
+
+// Writes the options, a list is written as the option repeated for each value.
+func writeIniOptions(b *strings.Builder, options map[string][]string) {
+	for _, key := range sortedKeys(options) {
+		for _, value := range options[key] {
+			fmt.Fprintf(b, "%s = %q\n", key, value)
+		}
+	}
+}
+
+// Returns the sorted keys of the map.
+func sortedKeys(m interface{}) (keys []string) {
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return
+}
+
+// EffectiveConfig returns the options of the command config in the layout of the project
+// configuration, options which cannot be set from the configuration are skipped.
+func EffectiveConfig(c *CommandConfig) map[string]interface{} {
+	return effectiveOptions(reflect.ValueOf(c).Elem())
+}
+
+// Returns the options of the struct, the commands are added as nested tables.
+func effectiveOptions(value reflect.Value) map[string]interface{} {
+	options := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		switch {
+		case field.Anonymous:
+			for key, option := range effectiveOptions(value.Field(i)) {
+				options[key] = option
+			}
+		case field.Tag.Get("command") != "":
+			if command := effectiveOptions(value.Field(i)); len(command) > 0 {
+				options[field.Tag.Get("command")] = command
+			}
+		case field.Tag.Get("long") != "" && field.Tag.Get("no-ini") == "":
+			options[field.Tag.Get("long")] = value.Field(i).Interface()
+		}
+	}
+	return options
+}
@@ -0,0 +1,88 @@
+package model_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProjectConfig = `
+build-flags:
+  - app.Env=dev
+run:
+  port: 9100
+  run-mode: prod
+  build-flags: [app.Env=run]
+test:
+  report: [html, junit]
+generate:
+  client:
+    target-path: client/client.go
+`
+
+// Test that the project configuration is found from a sub folder and converted to the ini format.
+func TestProjectConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-project")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	subDir := filepath.Join(dir, "app", "controllers")
+	assert.Nil(t, os.MkdirAll(subDir, 0777))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644))
+
+	config, err := model.FindProjectConfig(subDir)
+	assert.Nil(t, err)
+	assert.Nil(t, config, "No configuration should be found")
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "revel.yaml"), []byte(testProjectConfig), 0644))
+	config, err = model.FindProjectConfig(subDir)
+	if !assert.Nil(t, err) || !assert.NotNil(t, config) {
+		return
+	}
+	assert.Equal(t, filepath.Join(dir, "revel.yaml"), config.Path)
+	assert.Equal(t, []string{"9100"}, config.Sections["run"]["port"])
+	assert.Equal(t, []string{"client/client.go"}, config.Sections["generate.client"]["target-path"])
+
+	global := func(key string) bool { return key == "build-flags" }
+	assert.Equal(t, `build-flags = "app.Env=dev"
+
+[generate.client]
+target-path = "client/client.go"
+
+[run]
+port = "9100"
+run-mode = "prod"
+
+[test]
+report = "html"
+report = "junit"
+`, config.Ini("test", global))
+	assert.Contains(t, config.Ini("run", global), `build-flags = "app.Env=run"`, "The command should override the global")
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "revel.yaml"), []byte("run:\n  port: {a: [1]}\n  list: [[1]]\n"), 0644))
+	_, err = model.FindProjectConfig(dir)
+	assert.NotNil(t, err, "Nested lists should fail")
+}
+
+// Test that the effective configuration contains the options of the commands.
+func TestEffectiveConfig(t *testing.T) {
+	c := &model.CommandConfig{BuildFlags: []string{"app.Env=dev"}}
+	c.Run.Port = 9100
+	c.Run.Delve = true
+	c.Generate.Client.TargetPath = "client/client.go"
+
+	options := model.EffectiveConfig(c)
+	assert.Equal(t, []string{"app.Env=dev"}, options["build-flags"])
+	assert.NotContains(t, options, "ini")
+	assert.Equal(t, 9100, options["run"].(map[string]interface{})["port"])
+	assert.Equal(t, "", options["run"].(map[string]interface{})["application-path"])
+	// The debugger option does not share its name with the global verbose option
+	assert.Equal(t, true, options["run"].(map[string]interface{})["delve"])
+	assert.NotContains(t, options["run"], "debug")
+	assert.Equal(t, "client/client.go", options["generate"].(map[string]interface{})["client"].(map[string]interface{})["target-path"])
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/revel/cmd/utils"
+)
+
+// The names of the resolvers, selected with --resolver.
+const (
+	ResolverTidy = "tidy"
+	ResolverGet  = "get"
+	ResolverWork = "work"
+	ResolverNone = "none"
+	ResolverDeny = "deny"
+)
+
+const (
+	ErrResolverDenied  Error = "package resolving is disabled, missing packages are not fetched"
+	ErrInvalidPin      Error = "invalid pinned version, expected module@version"
+	ErrUnknownResolver Error = "unknown resolver"
+)
+
+type (
+	// Resolver fetches a package which could not be found, so the application can be built.
+	Resolver interface {
+		Resolve(pkgName string) error
+	}
+
+	// TidyResolver runs go mod tidy in the module, which adds the requirements of all the imports.
+	TidyResolver struct {
+		GoCmd string
+		Dir   string
+	}
+
+	// GetResolver runs go get for the package, at the version pinned for its module if there is one.
+	// For GOPATH applications the package is updated in the GOPATH instead.
+	GetResolver struct {
+		GoCmd    string
+		Dir      string
+		Versions map[string]string // The pinned versions by module path
+		GoPath   bool              // True if the application is not a module
+	}
+
+	// WorkResolver adds the package to the module and then syncs the go.work workspace, so the
+	// other modules in the workspace use the same versions.
+	WorkResolver struct {
+		GetResolver
+	}
+
+	// NoopResolver never fetches anything, the build reports the missing packages.
+	NoopResolver struct{}
+
+	// DenyResolver fails for every package, in offline mode the error explains how to fetch them.
+	DenyResolver struct {
+		Offline bool
+	}
+)
+
+// Resolve runs go mod tidy.
+func (r *TidyResolver) Resolve(pkgName string) error {
+	utils.Logger.Info("Resolving package with go mod tidy", "package", pkgName, "dir", r.Dir)
+	return runResolverCommand(exec.Command(r.GoCmd, "mod", "tidy", "-v"), false, r.Dir)
+}
+
+// Resolve runs go get for the package.
+func (r *GetResolver) Resolve(pkgName string) error {
+	if r.GoPath {
+		utils.Logger.Info("Resolving package in the GOPATH", "package", pkgName)
+		return runResolverCommand(exec.Command(r.GoCmd, "get", "-u", pkgName), true, r.Dir)
+	}
+	target := pkgName
+	if version := r.Version(pkgName); version != "" {
+		target += "@" + version
+	}
+	utils.Logger.Info("Resolving package with go get", "package", target, "dir", r.Dir)
+	return runResolverCommand(exec.Command(r.GoCmd, "get", target), false, r.Dir)
+}
+
+// Version returns the version pinned for the module of the package, the longest matching module
+// path is used. An empty string is returned if the package has no pinned version.
+func (r *GetResolver) Version(pkgName string) (version string) {
+	match := ""
+	for modulePath, pinned := range r.Versions {
+		if (pkgName == modulePath || strings.HasPrefix(pkgName, modulePath+"/")) && len(modulePath) > len(match) {
+			match, version = modulePath, pinned
+		}
+	}
+	return
+}
+
+// Resolve runs go get for the package and syncs the workspace.
+func (r *WorkResolver) Resolve(pkgName string) (err error) {
+	if err = r.GetResolver.Resolve(pkgName); err != nil {
+		return
+	}
+	utils.Logger.Info("Syncing the workspace", "dir", r.Dir)
+	return runResolverCommand(exec.Command(r.GoCmd, "work", "sync"), false, r.Dir)
+}
+
+// Resolve does nothing.
+func (r *NoopResolver) Resolve(pkgName string) error {
+	utils.Logger.Info("Not resolving package", "package", pkgName)
+	return nil
+}
+
+// Resolve returns an error for the package.
+func (r *DenyResolver) Resolve(pkgName string) error {
+	if r.Offline {
+		return OfflineError(pkgName)
+	}
+	return fmt.Errorf("%w: %s", ErrResolverDenied, pkgName)
+}
+
+// ParsePinnedVersions returns the versions by module path from the module@version values.
+func ParsePinnedVersions(pins []string) (versions map[string]string, err error) {
+	versions = map[string]string{}
+	for _, pin := range pins {
+		index := strings.LastIndex(pin, "@")
+		if index <= 0 || index == len(pin)-1 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPin, pin)
+		}
+		versions[pin[:index]] = pin[index+1:]
+	}
+	return
+}
+
+// Runs the go command in the folder, the output is returned in the error if it fails.
+func runResolverCommand(cmd *exec.Cmd, addGoPath bool, dir string) error {
+	utils.CmdInit(cmd, addGoPath, dir)
+	utils.Logger.Info("Exec:", "args", cmd.Args, "dir", cmd.Dir)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return utils.NewBuildError("Failed to resolve package", "command", strings.Join(cmd.Args, " "), "output", string(output), "error", err)
+	}
+	utils.Logger.Info("Resolved", "output", string(output))
+	return nil
+}
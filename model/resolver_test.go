@@ -0,0 +1,94 @@
+package model_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the pinned versions are parsed and matched to the longest module path.
+func TestPinnedVersions(t *testing.T) {
+	_, err := model.ParsePinnedVersions([]string{"github.com/revel/modules"})
+	assert.True(t, errors.Is(err, model.ErrInvalidPin))
+	_, err = model.ParsePinnedVersions([]string{"github.com/revel/modules@"})
+	assert.True(t, errors.Is(err, model.ErrInvalidPin))
+
+	versions, err := model.ParsePinnedVersions([]string{"github.com/revel/modules@v1.0.0", "github.com/revel/modules/static@v1.1.0"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	resolver := &model.GetResolver{Versions: versions}
+	assert.Equal(t, "v1.0.0", resolver.Version("github.com/revel/modules"))
+	assert.Equal(t, "v1.0.0", resolver.Version("github.com/revel/modules/jobs/app"))
+	assert.Equal(t, "v1.1.0", resolver.Version("github.com/revel/modules/static/app"))
+	assert.Equal(t, "", resolver.Version("github.com/revel/modulesx"))
+}
+
+// Test that the resolver is selected from the command config.
+func TestNewResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-resolver")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	c := &model.CommandConfig{GoCmd: "go", AppPath: dir}
+
+	resolver, err := c.NewResolver()
+	assert.Nil(t, err)
+	assert.IsType(t, &model.GetResolver{}, resolver, "GOPATH applications should use go get")
+
+	c.Vendored = true
+	resolver, _ = c.NewResolver()
+	assert.IsType(t, &model.TidyResolver{}, resolver, "Modules should be tidied")
+
+	for name, expected := range map[string]model.Resolver{
+		model.ResolverGet:  &model.GetResolver{},
+		model.ResolverWork: &model.WorkResolver{},
+		model.ResolverNone: &model.NoopResolver{},
+		model.ResolverDeny: &model.DenyResolver{},
+	} {
+		c.ResolverName = name
+		resolver, err = c.NewResolver()
+		assert.Nil(t, err)
+		assert.IsType(t, expected, resolver, name)
+	}
+	c.ResolverName = model.ResolverDeny
+	resolver, _ = c.NewResolver()
+	assert.True(t, errors.Is(resolver.Resolve("github.com/revel/modules"), model.ErrResolverDenied))
+
+	c.ResolverName = "unknown"
+	_, err = c.NewResolver()
+	assert.True(t, errors.Is(err, model.ErrUnknownResolver))
+
+	c.Offline = true
+	resolver, _ = c.NewResolver()
+	assert.True(t, errors.Is(resolver.Resolve("github.com/revel/modules"), model.ErrOffline), "Offline should deny every package")
+}
+
+// Test that offline mode turns the proxy off and keeps the go commands from updating the go.mod.
+func TestOfflineGoFlags(t *testing.T) {
+	for goFlags, expected := range map[string]string{
+		"":                  "-mod=readonly",
+		"-mod=mod":          "-mod=readonly",
+		"-race --mod=mod":   "-race -mod=readonly",
+		"-race":             "-race -mod=readonly",
+		"-mod=vendor -race": "-mod=vendor -race",
+		"-mod=readonly":     "-mod=readonly",
+	} {
+		t.Setenv("GOFLAGS", goFlags)
+		t.Setenv("GOPROXY", "direct")
+		t.Setenv("GOENV", "off")
+		c := &model.CommandConfig{Offline: true, Vendored: true}
+		c.InitPackageResolver()
+		assert.Equal(t, expected, os.Getenv("GOFLAGS"), goFlags)
+		assert.Equal(t, "off", os.Getenv("GOPROXY"), goFlags)
+
+		resolver, err := c.NewResolver()
+		assert.Nil(t, err)
+		assert.IsType(t, &model.DenyResolver{}, resolver)
+	}
+}
@@ -75,6 +75,8 @@ type (
 		HTTPSsl       bool                   // True if running https
 		HTTPSslCert   string                 // The SSL certificate
 		HTTPSslKey    string                 // The SSL key
+		ProxySslCert  string                 // The SSL certificate of the harness proxy, set by revel run --https
+		ProxySslKey   string                 // The SSL key of the harness proxy
 		AppName       string                 // The application name
 		AppRoot       string                 // The application root from the config `app.root`
 		CookiePrefix  string                 // The cookie prefix
@@ -83,6 +85,8 @@ type (
 		SecretStr     string                 // The secret string
 		MimeConfig    *config.Context        // The mime configuration
 		ModulePathMap map[string]*ModuleInfo // The module path map
+		Workspace     *Workspace             // The go.work workspace, if the application is part of one
+		ReplacePaths  []string               // The local folders of the replace directives in the go.mod
 	}
 	ModuleInfo struct {
 		ImportPath string
@@ -136,6 +140,14 @@ func NewRevelPaths(mode, importPath, appSrcPath string, callback RevelCallback)
 	// Setup paths for application
 	rp.BasePath = rp.SourcePath
 	rp.PackageInfo.Vendor = utils.Exists(filepath.Join(rp.BasePath, "go.mod"))
+	if rp.Workspace, err = FindWorkspace(rp.BasePath); err != nil {
+		return
+	}
+	if rp.PackageInfo.Vendor {
+		if rp.ReplacePaths, err = LocalReplacePaths(filepath.Join(rp.BasePath, "go.mod")); err != nil {
+			return
+		}
+	}
 	rp.AppPath = filepath.Join(rp.BasePath, "app")
 
 	// Sanity check , ensure app and conf paths exist
@@ -266,6 +278,26 @@ func (rp *RevelContainer) loadModules(callback RevelCallback) (err error) {
 	return
 }
 
+// ModuleWatchPaths returns the folders of the other modules the application is developed with,
+// the modules used by the go.work workspace and the local replace directives of the go.mod.
+func (rp *RevelContainer) ModuleWatchPaths() (paths []string) {
+	dirs := append([]string{}, rp.ReplacePaths...)
+	if rp.Workspace != nil {
+		for _, dir := range rp.Workspace.Modules {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		// Folders containing the application would watch it twice
+		containsApp := strings.HasPrefix(rp.BasePath+string(filepath.Separator), dir+string(filepath.Separator))
+		if !containsApp && !utils.ContainsString(paths, dir) {
+			paths = append(paths, dir)
+		}
+	}
+	sort.Strings(paths)
+	return
+}
+
 // Adds a module paths to the container object.
 func (rp *RevelContainer) addModulePaths(name, importPath, modulePath string) {
 	utils.Logger.Info("Adding module path", "name", name, "import path", importPath, "system path", modulePath)
@@ -294,6 +326,11 @@ func (rp *RevelContainer) ResolveImportPath(importPath string) (string, error) {
 	if rp.Packaged {
 		return filepath.Join(rp.SourcePath, importPath), nil
 	}
+	if rp.Workspace != nil {
+		if dir, found := rp.Workspace.PackageDir(importPath); found {
+			return dir, nil
+		}
+	}
 	config := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
 			packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo,
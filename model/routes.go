@@ -0,0 +1,150 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/revel/cmd/utils"
+)
+
+const routesModulePrefix = "module:"
+
+// Route describes a single entry read from a conf/routes file.
+type Route struct {
+	Method    string   // The http method, e.g. "GET", "*" for any
+	Path      string   // The full path, e.g. "/users/:id"
+	Action    string   // The action, e.g. "Users.Show"
+	FixedArgs []string // The fixed arguments, e.g. ["public"] for Static.Serve("public")
+	Module    string   // The module the route was loaded from, empty for the application
+	File      string   // The routes file the entry was read from
+	Line      int      // The line in the routes file
+}
+
+// Groups:
+// 1: method
+// 4: path
+// 5: action
+// 6: fixedargs
+// Kept in sync with the route pattern used by the framework router.
+var routePattern = regexp.MustCompile(
+	"(?i)^(GET|POST|PUT|DELETE|PATCH|OPTIONS|HEAD|WS|PROPFIND|MKCOL|COPY|MOVE|PROPPATCH|LOCK|UNLOCK|TRACE|PURGE|\\*)" +
+		"[(]?([^)]*)(\\))?[ \t]+" +
+		"(.*/[^ \t]*)[ \t]+([^ \t(]+)" +
+		`\(?([^)]*)\)?[ \t]*$`)
+
+// ControllerName returns the controller part of the action, without any module namespace.
+func (r *Route) ControllerName() string {
+	parts := strings.Split(r.Action, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
+// MethodName returns the method part of the action.
+func (r *Route) MethodName() string {
+	parts := strings.Split(r.Action, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+// IsVariable returns true if the controller or method is taken from the path (e.g. ":controller.:action"),
+// or if the action is not a controller action at all (e.g. a 404).
+func (r *Route) IsVariable() bool {
+	controller, method := r.ControllerName(), r.MethodName()
+	return controller == "" || method == "" || controller[0] == ':' || method[0] == ':'
+}
+
+// PathParams returns the names of the parameters embedded in the path, e.g. "id" for "/users/:id".
+func (r *Route) PathParams() (params []string) {
+	for _, segment := range strings.Split(r.Path, "/") {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, segment[1:])
+		}
+	}
+	return
+}
+
+// LoadRoutes reads the application routes file, including the routes of any
+// modules referenced using the "module:" syntax.
+func (rp *RevelContainer) LoadRoutes() ([]*Route, error) {
+	return rp.parseRoutesFile("", filepath.Join(rp.BasePath, "conf", "routes"), rp.AppRoot)
+}
+
+// Parses the routes file, prepending the joined path to all routes found.
+func (rp *RevelContainer) parseRoutesFile(moduleName, routesPath, joinedPath string) ([]*Route, error) {
+	content, err := ioutil.ReadFile(routesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes file %s: %w", routesPath, err)
+	}
+	routes := ParseRoutes(moduleName, routesPath, joinedPath, string(content))
+
+	// Expand the module routes in place
+	expanded := []*Route{}
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Action, routesModulePrefix) {
+			expanded = append(expanded, route)
+			continue
+		}
+		name := route.Action[len(routesModulePrefix):]
+		module, found := rp.ModulePathMap[name]
+		if !found {
+			// Same as the framework, inactive modules are skipped
+			utils.Logger.Info("Skipping routes for inactive module", "module", name)
+			continue
+		}
+		moduleRoutesPath := filepath.Join(module.Path, "conf", "routes")
+		if !utils.Exists(moduleRoutesPath) {
+			continue
+		}
+		moduleRoutes, err := rp.parseRoutesFile(name, moduleRoutesPath, route.Path)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, moduleRoutes...)
+	}
+	return expanded, nil
+}
+
+// ParseRoutes parses the content of a routes file. Module includes are returned as routes
+// with an action of "module:<name>" and the path they are mounted on.
+func ParseRoutes(moduleName, routesPath, joinedPath, content string) (routes []*Route) {
+	joinedPath = strings.TrimSuffix(joinedPath, "/")
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		// e.g. "module:testrunner" imports all routes from that module.
+		if strings.HasPrefix(line, routesModulePrefix) {
+			routes = append(routes, &Route{Method: "*", Path: joinedPath, Action: line, Module: moduleName, File: routesPath, Line: n + 1})
+			continue
+		}
+
+		matches := routePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		route := &Route{
+			Method: strings.ToUpper(matches[1]),
+			Path:   joinedPath + matches[4],
+			Action: matches[5],
+			Module: moduleName,
+			File:   routesPath,
+			Line:   n + 1,
+		}
+		if fixedArgs := strings.TrimSpace(matches[6]); fixedArgs != "" {
+			for _, arg := range strings.Split(fixedArgs, ",") {
+				route.FixedArgs = append(route.FixedArgs, strings.Trim(strings.TrimSpace(arg), `"'`))
+			}
+		}
+		routes = append(routes, route)
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRoutes = `
+# Routes
+GET     /                       App.Index
+GET     /users/:id              Users.Show
+post    /users                  Users.Create
+GET     /public/*filepath       Static.Serve("public")
+*       /tests                  module:testrunner
+*       /:controller/:action    :controller.:action
+module:jobs
+`
+
+// Test that the routes file is parsed into routes.
+func TestParseRoutes(t *testing.T) {
+	routes := model.ParseRoutes("", "conf/routes", "/root/", testRoutes)
+	if !assert.Len(t, routes, 7) {
+		return
+	}
+
+	assert.Equal(t, "/root/", routes[0].Path)
+	assert.Equal(t, "App", routes[0].ControllerName())
+	assert.Equal(t, "Index", routes[0].MethodName())
+	assert.Equal(t, 3, routes[0].Line)
+
+	assert.Equal(t, []string{"id"}, routes[1].PathParams())
+	assert.Equal(t, "POST", routes[2].Method)
+
+	assert.Equal(t, []string{"public"}, routes[3].FixedArgs)
+	assert.Equal(t, []string{"filepath"}, routes[3].PathParams())
+
+	assert.Equal(t, "module:testrunner", routes[4].Action)
+	assert.Equal(t, "/root/tests", routes[4].Path)
+	assert.True(t, routes[5].IsVariable())
+
+	assert.Equal(t, "module:jobs", routes[6].Action)
+	assert.Equal(t, "/root", routes[6].Path)
+}
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/revel/cmd/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SkeletonManifestFiles are the names of the manifest, looked for in the skeleton root.
+var SkeletonManifestFiles = []string{"skeleton.yaml", "skeleton.yml", "skeleton.toml"}
+
+const (
+	ErrSkeletonUnknownVariable Error = "unknown skeleton variable"
+	ErrSkeletonInvalidValue    Error = "invalid value for skeleton variable"
+)
+
+type (
+	// SkeletonManifest describes the variables, conditional files and hooks of a skeleton.
+	SkeletonManifest struct {
+		Name        string              `yaml:"name" toml:"name"`
+		Description string              `yaml:"description" toml:"description"`
+		Variables   []*SkeletonVariable `yaml:"variables" toml:"variables"`
+		Files       []*SkeletonFile     `yaml:"files" toml:"files"`
+		Hooks       SkeletonHooks       `yaml:"hooks" toml:"hooks"`
+		Path        string              `yaml:"-" toml:"-"` // The path the manifest was read from
+	}
+
+	// SkeletonVariable is a value passed to the skeleton templates.
+	SkeletonVariable struct {
+		Name     string   `yaml:"name" toml:"name"`         // The name used in the templates, e.g. {{.DBDriver}}
+		Prompt   string   `yaml:"prompt" toml:"prompt"`     // The question asked, defaults to the name
+		Type     string   `yaml:"type" toml:"type"`         // "string" (the default) or "bool"
+		Default  string   `yaml:"default" toml:"default"`   // The value used when none is given
+		Choices  []string `yaml:"choices" toml:"choices"`   // The allowed values, if any
+		Pattern  string   `yaml:"pattern" toml:"pattern"`   // A regular expression the value must match, if any
+		Required bool     `yaml:"required" toml:"required"` // True if the value may not be empty
+	}
+
+	// SkeletonFile is a file or folder (ending in "/") of the skeleton that is only created when the
+	// condition is true. The condition is a template expression, e.g. `ne .DBDriver "none"`.
+	SkeletonFile struct {
+		Path string `yaml:"path" toml:"path"`
+		When string `yaml:"when" toml:"when"`
+	}
+
+	// SkeletonHooks are the commands run by the shell in the application folder, each one is
+	// rendered as a template first so they may be made conditional. Empty commands are skipped.
+	SkeletonHooks struct {
+		PostCreate []string `yaml:"post_create" toml:"post_create"`
+	}
+)
+
+// LoadSkeletonManifest reads the manifest from the skeleton root, nil is returned if the skeleton has none.
+func LoadSkeletonManifest(skeletonPath string) (manifest *SkeletonManifest, err error) {
+	for _, name := range SkeletonManifestFiles {
+		path := filepath.Join(skeletonPath, name)
+		if !utils.Exists(path) {
+			continue
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, utils.NewBuildIfError(err, "Failed to read skeleton manifest", "path", path)
+		}
+
+		manifest = &SkeletonManifest{Path: path}
+		if filepath.Ext(name) == ".toml" {
+			err = toml.Unmarshal(content, manifest)
+		} else {
+			err = yaml.Unmarshal(content, manifest)
+		}
+		if err != nil {
+			return nil, utils.NewBuildIfError(err, "Failed to parse skeleton manifest", "path", path)
+		}
+		for _, variable := range manifest.Variables {
+			if variable.Pattern != "" {
+				if _, err = regexp.Compile(variable.Pattern); err != nil {
+					return nil, utils.NewBuildIfError(err, "Invalid pattern in skeleton manifest", "variable", variable.Name)
+				}
+			}
+		}
+		return manifest, nil
+	}
+	return
+}
+
+// Variable returns the variable with the name, or nil.
+func (m *SkeletonManifest) Variable(name string) *SkeletonVariable {
+	for _, variable := range m.Variables {
+		if variable.Name == name {
+			return variable
+		}
+	}
+	return nil
+}
+
+// Values validates the values and adds them to the data, the defaults are used for the
+// variables that have no value.
+func (m *SkeletonManifest) Values(values map[string]string, data map[string]interface{}) (err error) {
+	for name := range values {
+		if m.Variable(name) == nil {
+			return fmt.Errorf("%w: %s", ErrSkeletonUnknownVariable, name)
+		}
+	}
+	for _, variable := range m.Variables {
+		value, found := values[variable.Name]
+		if !found {
+			value = variable.Default
+		}
+		if data[variable.Name], err = variable.Parse(value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Parse validates the value, bool variables are converted to a bool.
+func (v *SkeletonVariable) Parse(value string) (interface{}, error) {
+	if value == "" && v.Required {
+		return nil, fmt.Errorf("%w %s: a value is required", ErrSkeletonInvalidValue, v.Name)
+	}
+	if v.Type == "bool" {
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %q is not true or false", ErrSkeletonInvalidValue, v.Name, value)
+		}
+		return b, nil
+	}
+	if len(v.Choices) > 0 && !utils.ContainsString(v.Choices, value) {
+		return nil, fmt.Errorf("%w %s: %q is not one of %s", ErrSkeletonInvalidValue, v.Name, value, strings.Join(v.Choices, ", "))
+	}
+	if v.Pattern != "" && !regexp.MustCompile(v.Pattern).MatchString(value) {
+		return nil, fmt.Errorf("%w %s: %q does not match %s", ErrSkeletonInvalidValue, v.Name, value, v.Pattern)
+	}
+	return value, nil
+}
+
+// Excluded returns true if the file or folder, relative to the skeleton root, should not be created.
+// The manifest itself is always excluded.
+func (m *SkeletonManifest) Excluded(relPath string, data map[string]interface{}) (bool, error) {
+	relPath = filepath.ToSlash(relPath)
+	if utils.ContainsString(SkeletonManifestFiles, relPath) {
+		return true, nil
+	}
+	for _, file := range m.Files {
+		if file.When == "" || !file.matches(relPath) {
+			continue
+		}
+		include, err := renderSkeletonTemplate("{{"+file.When+"}}", data)
+		if err != nil {
+			return false, err
+		}
+		if strings.TrimSpace(include) != "true" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Returns true if the path matches the file, the ".template" suffix is ignored.
+func (f *SkeletonFile) matches(relPath string) bool {
+	path := strings.TrimSuffix(filepath.ToSlash(f.Path), ".template")
+	relPath = strings.TrimSuffix(relPath, ".template")
+	if strings.HasSuffix(path, "/") {
+		return strings.HasPrefix(relPath+"/", path)
+	}
+	matched, _ := filepath.Match(path, relPath)
+	return matched || path == relPath
+}
+
+// PostCreateHooks returns the rendered post create commands.
+func (m *SkeletonManifest) PostCreateHooks(data map[string]interface{}) (hooks []string, err error) {
+	for _, hook := range m.Hooks.PostCreate {
+		rendered, err := renderSkeletonTemplate(hook, data)
+		if err != nil {
+			return nil, err
+		}
+		if rendered = strings.TrimSpace(rendered); rendered != "" {
+			hooks = append(hooks, rendered)
+		}
+	}
+	return
+}
+
+// Renders the template source with the data.
+func renderSkeletonTemplate(source string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", utils.NewBuildIfError(err, "Invalid template in skeleton manifest", "template", source)
+	}
+	var b bytes.Buffer
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", utils.NewBuildIfError(err, "Failed to render template in skeleton manifest", "template", source)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,126 @@
+package model_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSkeletonManifest = `
+name: basic
+variables:
+  - name: DBDriver
+    default: sqlite
+    choices: [sqlite, postgres, none]
+  - name: Auth
+    type: bool
+  - name: Org
+    pattern: "^[a-z]+$"
+    default: revel
+files:
+  - path: app/db.go.template
+    when: ne .DBDriver "none"
+  - path: app/auth/
+    when: .Auth
+hooks:
+  post_create:
+    - go mod tidy
+    - '{{if eq .DBDriver "postgres"}}go get github.com/lib/pq{{end}}'
+`
+
+// Test that the manifest is loaded and the values, files and hooks are resolved from it.
+func TestSkeletonManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-skeleton")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := model.LoadSkeletonManifest(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, manifest, "No manifest should be found")
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "skeleton.yaml"), []byte(testSkeletonManifest), 0644))
+	manifest, err = model.LoadSkeletonManifest(dir)
+	if !assert.Nil(t, err) || !assert.NotNil(t, manifest) {
+		return
+	}
+	assert.Len(t, manifest.Variables, 3)
+
+	data := map[string]interface{}{}
+	assert.NotNil(t, manifest.Values(map[string]string{"Unknown": "x"}, data), "Unknown variables should fail")
+	assert.NotNil(t, manifest.Values(map[string]string{"DBDriver": "oracle"}, data), "Values should be one of the choices")
+	assert.NotNil(t, manifest.Values(map[string]string{"Org": "Revel"}, data), "Values should match the pattern")
+	assert.NotNil(t, manifest.Values(map[string]string{"Auth": "maybe"}, data), "Bools should be parsed")
+
+	data = map[string]interface{}{}
+	assert.Nil(t, manifest.Values(map[string]string{"DBDriver": "postgres", "Auth": "true"}, data))
+	assert.Equal(t, map[string]interface{}{"DBDriver": "postgres", "Auth": true, "Org": "revel"}, data)
+
+	for path, expected := range map[string]bool{
+		"skeleton.yaml":     true,
+		"app/db.go":         false,
+		"app/auth":          false,
+		"app/auth/login.go": false,
+		"app/app.go":        false,
+	} {
+		excluded, err := manifest.Excluded(path, data)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, excluded, "Excluded %s", path)
+	}
+
+	hooks, err := manifest.PostCreateHooks(data)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go mod tidy", "go get github.com/lib/pq"}, hooks)
+
+	data = map[string]interface{}{}
+	assert.Nil(t, manifest.Values(map[string]string{"DBDriver": "none"}, data))
+	excluded, _ := manifest.Excluded("app/db.go.template", data)
+	assert.True(t, excluded)
+	excluded, _ = manifest.Excluded("app/auth/login.go", data)
+	assert.True(t, excluded)
+	hooks, _ = manifest.PostCreateHooks(data)
+	assert.Equal(t, []string{"go mod tidy"}, hooks)
+}
+
+// Test that the excluded files are not rendered when the skeleton is copied, so a template which
+// only works for other values does not fail.
+func TestSkeletonCopyExcluded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-skeleton")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	skeleton, app := filepath.Join(dir, "skeleton"), filepath.Join(dir, "app")
+	assert.Nil(t, os.MkdirAll(filepath.Join(skeleton, "app", "auth"), 0755))
+	for path, content := range map[string]string{
+		"skeleton.yaml":              testSkeletonManifest,
+		"app/app.go.template":        "package app // {{.Org}}\n",
+		"app/db.go.template":         `{{template "missing"}}`,
+		"app/auth/login.go.template": `{{template "missing"}}`,
+	} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(skeleton, filepath.FromSlash(path)), []byte(content), 0644))
+	}
+
+	manifest, err := model.LoadSkeletonManifest(skeleton)
+	if !assert.Nil(t, err) || !assert.NotNil(t, manifest) {
+		return
+	}
+	data := map[string]interface{}{}
+	assert.Nil(t, manifest.Values(map[string]string{"DBDriver": "none"}, data))
+	assert.Nil(t, utils.CopyDirFiltered(app, skeleton, data, func(relPath string) (bool, error) {
+		return manifest.Excluded(relPath, data)
+	}))
+
+	content, err := ioutil.ReadFile(filepath.Join(app, "app", "app.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, "package app // revel\n", string(content))
+	for _, path := range []string{"skeleton.yaml", "app/db.go", "app/auth"} {
+		assert.False(t, utils.Exists(filepath.Join(app, filepath.FromSlash(path))), path)
+	}
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/revel/cmd/utils"
+	"golang.org/x/mod/modfile"
+)
+
+// Workspace describes the go.work workspace the application is developed in.
+type Workspace struct {
+	Path    string            // The path of the go.work
+	Modules map[string]string // The folders of the used modules, by module path
+}
+
+// FindWorkspace looks for a go.work in the folder and its parents, the GOWORK environment
+// variable is used if it is set. Nil is returned if there is no workspace.
+func FindWorkspace(path string) (*Workspace, error) {
+	switch goWork := os.Getenv("GOWORK"); goWork {
+	case "off":
+		return nil, nil
+	case "":
+	default:
+		return LoadWorkspace(goWork)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if workPath := filepath.Join(path, "go.work"); utils.Exists(workPath) {
+			return LoadWorkspace(workPath)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return nil, nil
+		}
+		path = parent
+	}
+}
+
+// LoadWorkspace reads the go.work, the module paths are read from the go.mod in each used folder.
+func LoadWorkspace(path string) (*Workspace, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to read workspace", "path", path)
+	}
+	workFile, err := modfile.ParseWork(path, content, nil)
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to parse workspace", "path", path)
+	}
+
+	workspace := &Workspace{Path: path, Modules: map[string]string{}}
+	for _, use := range workFile.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		goMod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			utils.Logger.Warn("Skipping workspace folder without a go.mod", "workspace", path, "folder", dir)
+			continue
+		}
+		if modulePath := modfile.ModulePath(goMod); modulePath != "" {
+			workspace.Modules[modulePath] = dir
+		}
+	}
+	utils.Logger.Info("Loaded workspace", "path", path, "modules", workspace.Modules)
+	return workspace, nil
+}
+
+// Module returns the path and folder of the workspace module providing the import path, the
+// longest matching module path is used.
+func (w *Workspace) Module(importPath string) (modulePath, dir string, found bool) {
+	for path, moduleDir := range w.Modules {
+		if (importPath == path || strings.HasPrefix(importPath, path+"/")) && len(path) > len(modulePath) {
+			modulePath, dir, found = path, moduleDir, true
+		}
+	}
+	return
+}
+
+// PackageDir returns the folder of the package if it is provided by a workspace module.
+func (w *Workspace) PackageDir(importPath string) (string, bool) {
+	modulePath, dir, found := w.Module(importPath)
+	if !found {
+		return "", false
+	}
+	packageDir := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(importPath[len(modulePath):], "/")))
+	return packageDir, utils.DirExists(packageDir)
+}
+
+// LocalReplacePaths returns the folders of the replace directives in the go.mod which point to
+// a local folder, e.g. "replace example.com/shared => ../shared". Folders which do not exist are
+// skipped.
+func LocalReplacePaths(goModPath string) (paths []string, err error) {
+	content, err := ioutil.ReadFile(goModPath)
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to read go.mod", "path", goModPath)
+	}
+	modFile, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return nil, utils.NewBuildIfError(err, "Failed to parse go.mod", "path", goModPath)
+	}
+	for _, replace := range modFile.Replace {
+		if replace.New.Version != "" || !modfile.IsDirectoryPath(replace.New.Path) {
+			continue
+		}
+		dir := filepath.FromSlash(replace.New.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goModPath), dir)
+		}
+		if !utils.DirExists(dir) {
+			utils.Logger.Warn("Skipping replace directive for a missing folder", "module", replace.Old.Path, "folder", dir)
+			continue
+		}
+		paths = append(paths, dir)
+	}
+	return
+}
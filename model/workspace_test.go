@@ -0,0 +1,138 @@
+package model_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the workspace is found from a module folder and the packages resolve to the used folders.
+func TestWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	dir, err := ioutil.TempDir("", "revel-workspace")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	for path, content := range map[string]string{
+		"go.work":             "go 1.18\n\nuse (\n\t./app\n\t./shared\n\t./missing\n)\n",
+		"app/go.mod":          "module example.com/app\n",
+		"shared/go.mod":       "module example.com/shared\n",
+		"shared/util/util.go": "package util\n",
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0777))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	workspace, err := model.FindWorkspace(filepath.Join(dir, "app", "controllers"))
+	if !assert.Nil(t, err) || !assert.NotNil(t, workspace) {
+		return
+	}
+	assert.Equal(t, filepath.Join(dir, "go.work"), workspace.Path)
+	assert.Equal(t, map[string]string{
+		"example.com/app":    filepath.Join(dir, "app"),
+		"example.com/shared": filepath.Join(dir, "shared"),
+	}, workspace.Modules, "Folders without a go.mod should be skipped")
+
+	packageDir, found := workspace.PackageDir("example.com/shared/util")
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "shared", "util"), packageDir)
+	_, found = workspace.PackageDir("example.com/shared/none")
+	assert.False(t, found, "Missing folders should not be found")
+	_, _, found = workspace.Module("example.com/sharedx")
+	assert.False(t, found)
+
+	t.Setenv("GOWORK", "off")
+	workspace, err = model.FindWorkspace(filepath.Join(dir, "app"))
+	assert.Nil(t, err)
+	assert.Nil(t, workspace, "GOWORK=off should disable the workspace")
+}
+
+// Test that a malformed go.work fails the import path update instead of leaving the app path unset.
+func TestWorkspaceMalformed(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	dir, err := ioutil.TempDir("", "revel-workspace")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "app"), 0777))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.work"), []byte("go 1.18\n\nuse (\n"), 0644))
+
+	c := &model.CommandConfig{Index: model.BUILD}
+	c.Build.ImportPath = filepath.Join(dir, "app")
+	assert.NotNil(t, c.UpdateImportPath())
+	assert.Equal(t, "", c.AppPath)
+}
+
+// Test that only the replace directives pointing at existing local folders are returned.
+func TestLocalReplacePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-replace")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "shared"), 0777))
+	goMod := filepath.Join(dir, "app", "go.mod")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(goMod), 0777))
+	assert.Nil(t, ioutil.WriteFile(goMod, []byte(`module example.com/app
+
+replace (
+	example.com/shared => ../shared
+	example.com/missing => ../missing
+	example.com/fork => github.com/me/fork v1.0.0
+)
+`), 0644))
+
+	paths, err := model.LocalReplacePaths(goMod)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "shared")}, paths)
+
+	_, err = model.LocalReplacePaths(filepath.Join(dir, "go.mod"))
+	assert.NotNil(t, err, "A missing go.mod should fail")
+}
+
+// Test that the modules containing the application are not watched, the sibling modules are.
+func TestModuleWatchPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-watch")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	for _, path := range []string{"repo/web", "repo/shared", "repo/web2", "tools"} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, filepath.FromSlash(path)), 0777))
+	}
+	basePath := filepath.Join(dir, "repo", "web")
+	goMod := filepath.Join(basePath, "go.mod")
+	// The repository module contains the application, the application in web2 only shares the prefix
+	assert.Nil(t, ioutil.WriteFile(goMod, []byte(`module example.com/repo/web
+
+replace (
+	example.com/repo => ../
+	example.com/repo/shared => ../shared
+	example.com/repo/web2 => ../web2
+)
+`), 0644))
+
+	replacePaths, err := model.LocalReplacePaths(goMod)
+	assert.Nil(t, err)
+	rp := &model.RevelContainer{
+		BasePath:     basePath,
+		ReplacePaths: replacePaths,
+		Workspace: &model.Workspace{Modules: map[string]string{
+			"example.com/repo/web":    basePath,
+			"example.com/repo/shared": filepath.Join(dir, "repo", "shared"),
+			"example.com/tools":       filepath.Join(dir, "tools"),
+		}},
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "repo", "shared"),
+		filepath.Join(dir, "repo", "web2"),
+		filepath.Join(dir, "tools"),
+	}, rp.ModuleWatchPaths())
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"go/ast"
 	"go/token"
+	"strings"
 
 	"github.com/revel/cmd/model"
 	"github.com/revel/cmd/utils"
@@ -127,6 +128,7 @@ func appendAction(fset *token.FileSet, mm methodMap, decl ast.Decl, pkgImportPat
 
 	method := &model.MethodSpec{
 		Name: funcDecl.Name.Name,
+		Doc:  strings.TrimSpace(funcDecl.Doc.Text()),
 	}
 
 	// Add a description of the arguments to the method.
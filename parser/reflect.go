@@ -81,7 +81,7 @@ func (pc *processContainer) processPath(path string, info os.FileInfo, err error
 		func(f os.FileInfo) bool {
 			return !f.IsDir() && !strings.HasPrefix(f.Name(), ".") && strings.HasSuffix(f.Name(), ".go")
 		},
-		0)
+		parser.ParseComments)
 
 	if err != nil {
 		var errList scanner.ErrorList
@@ -91,6 +91,7 @@ func (pc *processContainer) processPath(path string, info os.FileInfo, err error
 				SourceType:  ".go source",
 				Title:       "Go Compilation Error",
 				Path:        pos.Filename,
+				AbsPath:     pos.Filename,
 				Description: errList[0].Msg,
 				Line:        pos.Line,
 				Column:      pos.Column,
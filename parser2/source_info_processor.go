@@ -206,6 +206,7 @@ func (s *SourceInfoProcessor) getControllerFunc(funcDecl *ast.FuncDecl, p *packa
 	}
 	method = &model.MethodSpec{
 		Name: funcDecl.Name.Name,
+		Doc:  strings.TrimSpace(funcDecl.Doc.Text()),
 	}
 
 	// Add a description of the arguments to the method.
@@ -74,6 +74,13 @@ func (s *SourceProcessor) parse() (compileError error) {
 	for _, module := range s.revelContainer.ModulePathMap {
 		s.sourceInfo.PackageMap[module.ImportPath] = getImportFromMap(module.ImportPath)
 	}
+	if workspace := s.revelContainer.Workspace; workspace != nil {
+		for modulePath, dir := range workspace.Modules {
+			if s.sourceInfo.PackageMap[modulePath] == "" {
+				s.sourceInfo.PackageMap[modulePath] = dir
+			}
+		}
+	}
 
 	return
 }
@@ -149,7 +156,7 @@ func (s *SourceProcessor) processPath(path string, info os.FileInfo, err error)
 		func(f os.FileInfo) bool {
 			return !f.IsDir() && !strings.HasPrefix(f.Name(), ".") && strings.HasSuffix(f.Name(), ".go")
 		},
-		0)
+		parser.ParseComments)
 
 	if err != nil {
 		var errList scanner.ErrorList
@@ -159,6 +166,7 @@ func (s *SourceProcessor) processPath(path string, info os.FileInfo, err error)
 				SourceType:  ".go source",
 				Title:       "Go Compilation Error",
 				Path:        pos.Filename,
+				AbsPath:     pos.Filename,
 				Description: errList[0].Msg,
 				Line:        pos.Line,
 				Column:      pos.Column,
@@ -26,6 +26,27 @@ For example:
 
     revel build github.com/revel/examples/chat /tmp/chat
 
+The Go templates of app/views and the modules are parsed during the build, a
+syntax error fails it and a {{template}} of a missing template is a warning.
+
+Shell commands in app.conf are run during the build, in the application folder,
+with REVEL_EVENT, REVEL_APP_PATH, REVEL_IMPORT_PATH, REVEL_RUN_MODE and
+REVEL_EVENT_VALUE in the environment. A failing command stops the build.
+
+    hook.build.pre = make assets          # Before the source is processed
+    hook.build.source = go generate ./... # After the source is processed
+    hook.build.post = ./scripts/check.sh  # After the binary is built, the value is its path
+    hook.build.target = ./scripts/copy.sh # After the target folder is written, the value is its path
+
+revel package runs hook.package.pre before the build and hook.package.post
+after the archive is created, the value is its path.
+
+Set build.assets = true in app.conf to add a copy of every asset of public
+named with the hash of its content, e.g. css/app.5d41402a.css, with the CSS
+and JS copies minified and .gz and .br siblings to serve with a long cache
+lifetime. The assets themselves are left as they are. public/assets-manifest.json maps each asset to its copy. revel run
+writes the manifest with every asset mapped to itself to
+app/tmp/assets-manifest.json, so the source of the app is left alone.
 `,
 }
 
@@ -98,10 +119,15 @@ func buildApp(c *model.CommandConfig) (err error) {
 	if err != nil {
 		return
 	}
+	err = buildAssets(c, revelPaths)
+	if err != nil {
+		return
+	}
 	err = buildWriteScripts(c, app)
 	if err != nil {
 		return
 	}
+	err = harness.RunHooks(revelPaths, model.BUILD_TARGET_CREATED, c.Build.TargetPath)
 	return
 }
 
@@ -153,6 +179,20 @@ func buildCopyFiles(c *model.CommandConfig, app *harness.App, revelPaths *model.
 	return
 }
 
+// Minify and fingerprint the public folder of the target, if build.assets is set.
+func buildAssets(c *model.CommandConfig, revelPaths *model.RevelContainer) (err error) {
+	if !revelPaths.Config.BoolDefault("build.assets", false) {
+		return
+	}
+	publicPath := filepath.Join(c.Build.TargetPath, "src", filepath.FromSlash(c.ImportPath), "public")
+	manifestPath := filepath.Join(publicPath, harness.AssetsManifest)
+	manifest, err := harness.BuildAssets(publicPath, manifestPath, true)
+	if err == nil && manifest != nil {
+		utils.Logger.Info("Built the assets", "count", len(manifest), "manifest", manifestPath)
+	}
+	return
+}
+
 // Based on the section copy over the build modules.
 func buildCopyModules(c *model.CommandConfig, revelPaths *model.RevelContainer, packageFolders []string, app *harness.App) (err error) {
 	destPath := filepath.Join(c.Build.TargetPath, "src")
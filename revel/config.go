@@ -0,0 +1,138 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jessevdk/go-flags"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+var cmdConfig = &Command{
+	UsageLine: "config show [-a [application path]]",
+	Short:     "show the configuration of the command line tool",
+	Long: `
+Prints the options used by the commands, merged from the project configuration
+and the command line.
+
+The project configuration is read from a .revel.toml, revel.toml, .revel.yaml
+or revel.yaml in the application folder or its parents (up to the folder with
+the go.mod). The keys are the long option names, top level keys are the global
+options and each command has a table of its own. Global options set in the
+table of a command apply when that command is run. Options given on the
+command line override the values from the file. For example:
+
+    build-flags = ["github.com/myorg/myapp/app.Env=dev"]
+
+    [run]
+    run-mode = "dev"
+    port = 9100
+
+    [test]
+    report = ["html", "junit"]
+
+    [generate.client]
+    target-path = "client/client.go"
+
+An ini file given with --ini is applied after the project configuration.
+
+For example:
+
+    revel config show -a github.com/revel/examples/booking
+`,
+}
+
+func init() {
+	cmdConfig.RunWith = showConfig
+	cmdConfig.UpdateConfig = updateConfigConfig
+}
+
+// Update the config command configuration.
+func updateConfigConfig(c *model.CommandConfig, args []string) bool {
+	c.Index = model.CONFIG
+	if len(args) > 0 {
+		c.Config.ImportPath = args[0]
+	}
+	return true
+}
+
+// Prints the merged configuration in the format of the project configuration.
+func showConfig(c *model.CommandConfig) (err error) {
+	if c.ProjectConfig != nil {
+		fmt.Println("# Project configuration:", c.ProjectConfig.Path)
+	} else {
+		fmt.Println("# No project configuration found, showing the defaults")
+	}
+	if c.Ini != "" {
+		fmt.Println("# Ini file:", c.Ini)
+	}
+	fmt.Println()
+
+	options := model.EffectiveConfig(c)
+	delete(options, "config")
+	encoder := toml.NewEncoder(os.Stdout)
+	encoder.Indent = ""
+	if err = encoder.Encode(options); err != nil {
+		return utils.NewBuildIfError(err, "Failed to write configuration")
+	}
+	return
+}
+
+// Applies the project configuration and the ini file to the parser, so the values become the
+// defaults of the options. The arguments are parsed by a separate parser first to find the
+// command being run and the application folder, without setting any value.
+func applyProjectConfig(c *model.CommandConfig, parser *flags.Parser, args []string) (err error) {
+	probe := &model.CommandConfig{}
+	probeParser := flags.NewParser(probe, flags.IgnoreUnknown|flags.PassDoubleDash)
+	extraArgs, _ := probeParser.ParseArgs(args)
+	if probeParser.Active == nil {
+		// The parser reports the missing command
+		return nil
+	}
+
+	command := probeParser.Active.Name
+	if probeParser.Active.Active != nil {
+		command += "." + probeParser.Active.Active.Name
+	}
+	appPath := "."
+	if option := probeParser.Active.FindOptionByLongName("application-path"); option != nil {
+		if path, _ := option.Value().(string); utils.DirExists(path) {
+			appPath = path
+		}
+	}
+	if appPath == "." && len(extraArgs) > 0 && utils.DirExists(extraArgs[0]) {
+		appPath = extraArgs[0]
+	}
+
+	if c.ProjectConfig, err = model.FindProjectConfig(appPath); err != nil {
+		return
+	}
+	if c.ProjectConfig != nil {
+		ini := c.ProjectConfig.Ini(command, func(key string) bool {
+			return parser.Command.Group.FindOptionByLongName(key) != nil
+		})
+		utils.Logger.Info("Applying project configuration", "path", c.ProjectConfig.Path, "command", command)
+		if err = flags.NewIniParser(parser).Parse(strings.NewReader(ini)); err != nil {
+			// The line numbers are of the converted configuration, so only the message is kept
+			if iniErr, ok := err.(*flags.IniError); ok {
+				err = errors.New(iniErr.Message)
+			}
+			return fmt.Errorf("%w %s: %v", ErrInvalidProjectConfig, c.ProjectConfig.Path, err)
+		}
+	}
+
+	if probe.Ini != "" {
+		if err = flags.NewIniParser(parser).ParseFile(probe.Ini); err != nil {
+			return fmt.Errorf("%w %s: %v", ErrInvalidProjectConfig, probe.Ini, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/revel/cmd"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/revel/config"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	ErrDoctorFailed    Error = "doctor found problems"
+	ErrDoctorNoResolve Error = "package not found, the doctor does not fetch packages"
+)
+
+// The status of a doctor check.
+const (
+	doctorPass = "PASS"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+)
+
+// The minimum length of the app.secret before a warning is given.
+const doctorMinSecretLength = 32
+
+var cmdDoctor = &Command{
+	UsageLine: "doctor [-m [run mode]] [import path]",
+	Short:     "check the environment and the Revel application for problems",
+	Long: `
+Checks the Go installation, the module setup, the Revel framework and modules
+and the app.conf of the Revel application for problems. Each check reports a
+pass, warn or fail line with a hint on how to fix it.
+
+For example:
+
+    revel doctor -a github.com/revel/examples/booking -m prod
+`,
+}
+
+type (
+	// The result of a single doctor check.
+	doctorResult struct {
+		Status  string
+		Name    string
+		Message string
+		Hint    string
+	}
+
+	// The state shared between the doctor checks.
+	doctor struct {
+		c         *model.CommandConfig
+		results   []*doctorResult
+		revelPath string          // The path of the framework, if resolved
+		config    *config.Context // The app.conf, if loaded
+	}
+)
+
+var goVersionPattern = regexp.MustCompile(`go(\d+)\.(\d+)(?:\.(\d+))?`)
+
+func init() {
+	cmdDoctor.RunWith = doctorApp
+	cmdDoctor.UpdateConfig = updateDoctorConfig
+}
+
+// Update the doctor command configuration.
+func updateDoctorConfig(c *model.CommandConfig, args []string) bool {
+	c.Index = model.DOCTOR
+	if len(args) > 0 {
+		c.Doctor.ImportPath = args[0]
+	}
+	if c.Doctor.ImportPath == "" {
+		c.Doctor.ImportPath, _ = os.Getwd()
+	}
+	return true
+}
+
+// Called to run the checks, an error is returned if any of them failed.
+func doctorApp(c *model.CommandConfig) (err error) {
+	d := &doctor{c: c}
+	d.checkGoVersion()
+	d.checkGoMod()
+	d.checkFramework()
+	if d.loadConfig() {
+		d.checkModules()
+		d.checkPort()
+		d.checkSSL()
+		d.checkSecret()
+	}
+
+	failed := 0
+	for _, result := range d.results {
+		fmt.Printf("[%s] %-18s %s\n", result.Status, result.Name, result.Message)
+		if result.Hint != "" && result.Status != doctorPass {
+			fmt.Printf("       %-18s fix: %s\n", "", result.Hint)
+		}
+		if result.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d failed", ErrDoctorFailed, failed)
+	}
+	return
+}
+
+// Adds the result of a check.
+func (d *doctor) report(status, name, message, hint string) {
+	d.results = append(d.results, &doctorResult{Status: status, Name: name, Message: message, Hint: hint})
+}
+
+// Checks the Go version against the minimum version.
+func (d *doctor) checkGoVersion() {
+	const name = "Go version"
+	output, err := exec.Command(model.FirstNonEmpty(d.c.GoCmd, "go"), "version").Output()
+	if err != nil {
+		d.report(doctorFail, name, "go executable not found", "install Go and add it to the PATH")
+		return
+	}
+	minimum := goVersionPattern.FindStringSubmatch(cmd.MinimumGoVersion)
+	current := goVersionPattern.FindStringSubmatch(string(output))
+	if current == nil || minimum == nil {
+		d.report(doctorWarn, name, "unable to determine version from "+strings.TrimSpace(string(output)), "")
+		return
+	}
+	if compareGoVersions(current, minimum) < 0 {
+		d.report(doctorFail, name, current[0]+" is older than required "+cmd.MinimumGoVersion,
+			"install "+minimum[0]+" or newer from https://go.dev/dl")
+		return
+	}
+	d.report(doctorPass, name, current[0]+" ("+cmd.MinimumGoVersion+")", "")
+}
+
+// Returns -1, 0 or 1 comparing the matched go versions.
+func compareGoVersions(a, b []string) int {
+	for i := 1; i < 4; i++ {
+		x, _ := strconv.Atoi(a[i])
+		y, _ := strconv.Atoi(b[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Checks the go.mod exists and the module path matches the import path.
+func (d *doctor) checkGoMod() {
+	const name = "go.mod"
+	goModPath := filepath.Join(d.c.AppPath, "go.mod")
+	content, err := ioutil.ReadFile(goModPath)
+	if err != nil {
+		d.report(doctorWarn, name, "not found in "+d.c.AppPath+", using GOPATH mode",
+			"run 'go mod init "+d.c.ImportPath+"' in the application folder")
+		return
+	}
+	modulePath := modfile.ModulePath(content)
+	if modulePath == "" {
+		d.report(doctorFail, name, "no module directive in "+goModPath, "add 'module "+d.c.ImportPath+"' to the go.mod")
+		return
+	}
+
+	// An import path given on the command line should match the module
+	requested := d.c.Doctor.ImportPath
+	if requested != "" && !filepath.IsAbs(requested) && requested[0] != '.' && requested != modulePath {
+		d.report(doctorFail, name, "module "+modulePath+" does not match import path "+requested,
+			"use 'revel doctor -a "+modulePath+"' or change the module directive in "+goModPath)
+		return
+	}
+	d.report(doctorPass, name, "module "+modulePath, "")
+}
+
+// Checks the revel framework resolves and is compatible with this tool.
+func (d *doctor) checkFramework() {
+	const name = "Revel framework"
+	pathMap, err := utils.FindSrcPaths(d.c.AppPath, []string{model.RevelImportPath}, doctorNoResolve)
+	if err != nil || pathMap[model.RevelImportPath] == "" {
+		d.report(doctorFail, name, model.RevelImportPath+" could not be resolved",
+			"run 'go get "+model.RevelImportPath+"' in the application folder")
+		return
+	}
+	d.revelPath = pathMap[model.RevelImportPath]
+
+	// Resolving with the doctor resolver never fetches packages
+	resolver := d.c.PackageResolver
+	d.c.PackageResolver = doctorNoResolve
+	err = d.c.SetVersions()
+	d.c.PackageResolver = resolver
+	if err != nil || d.c.FrameworkVersion == nil {
+		d.report(doctorFail, name, "unable to read the version from "+d.revelPath, "run 'go mod tidy' to repair the module cache")
+		return
+	}
+	if err = d.c.FrameworkVersion.CompatibleFramework(d.c); err != nil {
+		d.report(doctorFail, name, d.c.FrameworkVersion.VersionString()+" is not compatible with tool "+cmd.Version,
+			"update the tool with 'go install github.com/revel/cmd/revel@latest' or use a supported framework version")
+		return
+	}
+	d.report(doctorPass, name, d.c.FrameworkVersion.VersionString()+" at "+d.revelPath, "")
+}
+
+// Loads the app.conf in the run mode, returns false if it could not be loaded.
+func (d *doctor) loadConfig() bool {
+	const name = "app.conf"
+	confPaths := []string{filepath.Join(d.c.AppPath, "conf")}
+	if d.revelPath != "" {
+		confPaths = append([]string{filepath.Join(d.revelPath, "conf")}, confPaths...)
+	}
+	conf, err := config.LoadContext("app.conf", confPaths)
+	if err != nil {
+		d.report(doctorFail, name, "unable to load: "+err.Error(), "check "+filepath.Join(d.c.AppPath, "conf", "app.conf")+" exists and is valid")
+		return false
+	}
+	mode := model.FirstNonEmpty(d.c.Doctor.Mode, config.DefaultSection)
+	if !conf.HasSection(mode) {
+		d.report(doctorFail, name, "run mode "+mode+" not found", "add a ["+mode+"] section to app.conf")
+		return false
+	}
+	conf.SetSection(mode)
+	d.config = conf
+	d.report(doctorPass, name, "loaded in run mode "+mode, "")
+	return true
+}
+
+// Checks every module in app.conf resolves, each one which does not is reported.
+func (d *doctor) checkModules() {
+	const name = "Modules"
+	keys := d.config.Options("module.")
+	sort.Strings(keys)
+	rp := &model.RevelContainer{SourcePath: d.c.AppPath, BasePath: d.c.AppPath, AppPath: filepath.Join(d.c.AppPath, "app")}
+	count, failed := 0, 0
+	for _, key := range keys {
+		importPath := d.config.StringDefault(key, "")
+		if importPath == "" {
+			continue
+		}
+		count++
+		if _, err := rp.ResolveImportPath(importPath); err != nil {
+			d.report(doctorFail, name, key+" = "+importPath+" could not be resolved",
+				"run 'go get "+importPath+"' in the application folder, or remove "+key+" from app.conf")
+			failed++
+		}
+	}
+	if failed == 0 {
+		d.report(doctorPass, name, fmt.Sprintf("%d resolved", count), "")
+	}
+}
+
+// Checks the http port is free.
+func (d *doctor) checkPort() {
+	const name = "HTTP port"
+	addr := net.JoinHostPort(d.config.StringDefault("http.addr", ""), strconv.Itoa(d.config.IntDefault("http.port", 9000)))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		d.report(doctorFail, name, addr+" is not available: "+err.Error(),
+			"stop the process using the port, or change http.port in app.conf")
+		return
+	}
+	_ = listener.Close()
+	d.report(doctorPass, name, addr+" is free", "")
+}
+
+// Checks the certificate and key exist when ssl is on.
+func (d *doctor) checkSSL() {
+	const name = "SSL"
+	if !d.config.BoolDefault("http.ssl", false) {
+		d.report(doctorPass, name, "http.ssl is off", "")
+		return
+	}
+	for _, key := range []string{"http.sslcert", "http.sslkey"} {
+		path := d.config.StringDefault(key, "")
+		if path == "" {
+			d.report(doctorFail, name, key+" is not set", "set "+key+" in app.conf or turn off http.ssl")
+			return
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(d.c.AppPath, path)
+		}
+		if !utils.Exists(path) {
+			d.report(doctorFail, name, key+" file "+path+" does not exist", "create the file or correct "+key+" in app.conf")
+			return
+		}
+	}
+	d.report(doctorPass, name, "certificate and key found", "")
+}
+
+// Checks the app.secret is long enough.
+func (d *doctor) checkSecret() {
+	const name = "app.secret"
+	secret := d.config.StringDefault("app.secret", "")
+	switch {
+	case secret == "":
+		d.report(doctorFail, name, "is not set", "set app.secret = "+generateSecret())
+	case len(secret) < doctorMinSecretLength:
+		d.report(doctorWarn, name, fmt.Sprintf("is only %d characters", len(secret)), "set app.secret = "+generateSecret())
+	default:
+		d.report(doctorPass, name, fmt.Sprintf("%d characters", len(secret)), "")
+	}
+}
+
+// A package resolver which never fetches the package.
+func doctorNoResolve(pkgName string) error {
+	return fmt.Errorf("%w: %s", ErrDoctorNoResolve, pkgName)
+}
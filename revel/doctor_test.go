@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a doctor for an application in a temp folder with the files, and the app.conf options.
+func newTestDoctor(t *testing.T, files map[string]string, options map[string]string) *doctor {
+	appPath, err := ioutil.TempDir("", "revel-doctor")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(appPath) })
+	for name, content := range files {
+		path := filepath.Join(appPath, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	conf := config.NewContext()
+	for key, value := range options {
+		conf.SetOption(key, value)
+	}
+	c := &model.CommandConfig{Index: model.DOCTOR, AppPath: appPath, ImportPath: "example.com/app"}
+	c.Doctor.ImportPath = "example.com/app"
+	return &doctor{c: c, config: conf}
+}
+
+// Returns the only result of the doctor.
+func doctorResultOf(t *testing.T, d *doctor) *doctorResult {
+	if !assert.Len(t, d.results, 1) {
+		return &doctorResult{}
+	}
+	return d.results[0]
+}
+
+func TestCompareGoVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"go1.9", "go1.17.3", -1},
+		{"go1.17.3", "go1.9", 1},
+		{"go1.17", "go1.17.0", 0},
+		{"go1.17.3", "go1.17.10", -1},
+		{"go version go1.22.1 linux/amd64", "go1.17", 1},
+	} {
+		a, b := goVersionPattern.FindStringSubmatch(test.a), goVersionPattern.FindStringSubmatch(test.b)
+		assert.Equal(t, test.expected, compareGoVersions(a, b), test.a+" "+test.b)
+	}
+}
+
+func TestDoctorGoMod(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		goMod     string
+		requested string
+		status    string
+	}{
+		{"missing", "", "", doctorWarn},
+		{"no module", "go 1.17\n", "", doctorFail},
+		{"mismatch", "module example.com/other\n", "example.com/app", doctorFail},
+		{"folder", "module example.com/other\n", "./app", doctorPass},
+		{"match", "module example.com/app\n", "example.com/app", doctorPass},
+	} {
+		files := map[string]string{}
+		if test.goMod != "" {
+			files["go.mod"] = test.goMod
+		}
+		d := newTestDoctor(t, files, nil)
+		d.c.Doctor.ImportPath = test.requested
+		d.checkGoMod()
+		assert.Equal(t, test.status, doctorResultOf(t, d).Status, test.name)
+	}
+}
+
+func TestDoctorSSL(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		options map[string]string
+		status  string
+	}{
+		{"off", nil, nil, doctorPass},
+		{"not set", nil, map[string]string{"http.ssl": "true"}, doctorFail},
+		{"missing cert", map[string]string{"conf/key.pem": "key"},
+			map[string]string{"http.ssl": "true", "http.sslcert": "conf/cert.pem", "http.sslkey": "conf/key.pem"}, doctorFail},
+		{"missing key", map[string]string{"conf/cert.pem": "cert"},
+			map[string]string{"http.ssl": "true", "http.sslcert": "conf/cert.pem", "http.sslkey": "conf/key.pem"}, doctorFail},
+		{"found", map[string]string{"conf/cert.pem": "cert", "conf/key.pem": "key"},
+			map[string]string{"http.ssl": "true", "http.sslcert": "conf/cert.pem", "http.sslkey": "conf/key.pem"}, doctorPass},
+	} {
+		d := newTestDoctor(t, test.files, test.options)
+		d.checkSSL()
+		assert.Equal(t, test.status, doctorResultOf(t, d).Status, test.name)
+	}
+}
+
+func TestDoctorSecret(t *testing.T) {
+	for _, test := range []struct {
+		secret string
+		status string
+	}{
+		{"", doctorFail},
+		{"short", doctorWarn},
+		{"0123456789abcdef0123456789abcdef", doctorPass},
+	} {
+		d := newTestDoctor(t, nil, map[string]string{"app.secret": test.secret})
+		d.checkSecret()
+		assert.Equal(t, test.status, doctorResultOf(t, d).Status, test.secret)
+	}
+}
+
+func TestDoctorPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	options := map[string]string{"http.addr": "localhost", "http.port": strconv.Itoa(port)}
+
+	d := newTestDoctor(t, nil, options)
+	d.checkPort()
+	assert.Equal(t, doctorFail, doctorResultOf(t, d).Status, "The port is busy")
+
+	assert.Nil(t, listener.Close())
+	d = newTestDoctor(t, nil, options)
+	d.checkPort()
+	assert.Equal(t, doctorPass, doctorResultOf(t, d).Status, "The port is free")
+}
+
+func TestDoctorModules(t *testing.T) {
+	d := newTestDoctor(t, nil, map[string]string{"module.empty": ""})
+	d.checkModules()
+	assert.Equal(t, &doctorResult{Status: doctorPass, Name: "Modules", Message: "0 resolved"}, doctorResultOf(t, d))
+
+	// Every module which does not resolve is reported
+	d = newTestDoctor(t, nil, map[string]string{"module.first": "example.com/missing/first", "module.second": "example.com/missing/second"})
+	d.checkModules()
+	if assert.Len(t, d.results, 2) {
+		for i, key := range []string{"module.first", "module.second"} {
+			assert.Equal(t, doctorFail, d.results[i].Status)
+			assert.Contains(t, d.results[i].Message, key)
+		}
+	}
+}
+
+// Test that the doctor fails when a check fails, the framework does not resolve in the empty
+// folder without fetching it.
+func TestDoctorApp(t *testing.T) {
+	d := newTestDoctor(t, map[string]string{"go.mod": "module example.com/app\n"}, nil)
+	err := doctorApp(d.c)
+	assert.True(t, errors.Is(err, ErrDoctorFailed), "%v", err)
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+)
+
+var cmdGenerate = &Command{
+	UsageLine: "generate [client|controller|model|test] [-n] [name] [field:type ...] [import path]",
+	Short:     "generate code for a Revel application",
+	Long: `
+Generates code from the controllers and routes of the Revel application, or
+generates new files for the application. The command may be shortened to "gen".
+
+The client generator writes a Go package with one method per controller action
+that has a route, the urls are created from the routes file and the arguments
+are typed from the action arguments. Struct arguments are sent as json.
+
+The controller generator writes a controller with CRUD actions, the matching
+views and adds the routes for the actions to the routes file. The model
+generator writes a model struct with the given fields, the field types are
+builtin types or types of the models package. The test generator writes a test
+suite under tests.
+
+Existing files are never overwritten, use -n to print the files that would be
+generated without writing them.
+
+The application is the one in the current folder, unless its import path is
+given after the name, or after the fields of the model.
+
+For example:
+
+    revel gen client -a github.com/revel/examples/booking -t ../bookingclient/client.go
+    revel gen controller Users
+    revel gen model User name:string age:int
+    revel gen model User name:string github.com/revel/examples/booking
+    revel gen test Users
+`,
+}
+
+//go:embed generators
+var generatorFS embed.FS
+
+// The field passed to the model generator.
+type generatorField struct {
+	Name string
+	Type string
+	JSON string
+}
+
+func init() {
+	cmdGenerate.RunWith = generateApp
+	cmdGenerate.UpdateConfig = updateGenerateConfig
+}
+
+// Update the generate command configuration.
+func updateGenerateConfig(c *model.CommandConfig, args []string) bool {
+	c.Index = model.GENERATE
+	if c.Generate.Generator == "client" {
+		if len(args) > 0 {
+			c.Generate.ImportPath = args[0]
+		}
+	} else {
+		// The other generators require a name
+		if len(args) == 0 {
+			return false
+		}
+		// The import path follows the name, or the fields of the model, which are identifiers
+		if last := args[len(args)-1]; len(args) > 1 && (c.Generate.Generator != "model" || isImportPathArg(last)) {
+			c.Generate.ImportPath, args = last, args[:len(args)-1]
+		}
+		c.Generate.Args = args
+	}
+	if c.Generate.ImportPath == "" {
+		c.Generate.ImportPath, _ = os.Getwd()
+	}
+	return true
+}
+
+// Returns true if the argument of the model generator is an import path, not a field.
+func isImportPathArg(arg string) bool {
+	return !strings.Contains(arg, ":") && !token.IsIdentifier(arg)
+}
+
+// Called to run the selected generator.
+func generateApp(c *model.CommandConfig) (err error) {
+	if c.Generate.Generator == "client" {
+		return generateClient(c)
+	}
+
+	name := c.Generate.Args[0]
+	if !token.IsIdentifier(name) {
+		return utils.NewBuildError("Invalid name, must be a Go identifier", "name", name)
+	}
+	name = strings.ToUpper(name[:1]) + name[1:]
+	data := map[string]interface{}{
+		"AppName":    c.AppName,
+		"ImportPath": c.ImportPath,
+		"Name":       name,
+		"Path":       strings.ToLower(name),
+		"File":       strings.ToLower(name),
+	}
+
+	switch c.Generate.Generator {
+	case "controller":
+		if err = generateFiles(c, "controller", data); err != nil {
+			return
+		}
+		return addGeneratedRoutes(c, []string{
+			routeLine("GET", "/%s", name+".Index", data),
+			routeLine("GET", "/%s/new", name+".New", data),
+			routeLine("POST", "/%s", name+".Create", data),
+			routeLine("GET", "/%s/:id", name+".Show", data),
+			routeLine("GET", "/%s/:id/edit", name+".Edit", data),
+			routeLine("POST", "/%s/:id/edit", name+".Update", data),
+			routeLine("POST", "/%s/:id/delete", name+".Delete", data),
+		})
+	case "model":
+		fields := []*generatorField{}
+		for _, arg := range c.Generate.Args[1:] {
+			parts := strings.SplitN(arg, ":", 2)
+			if !token.IsIdentifier(parts[0]) {
+				return utils.NewBuildError("Invalid field name, must be a Go identifier", "field", arg)
+			}
+			field := &generatorField{Name: strings.ToUpper(parts[0][:1]) + parts[0][1:], Type: "string", JSON: parts[0]}
+			if len(parts) > 1 && parts[1] != "" {
+				field.Type = parts[1]
+			}
+			if !isFieldType(field.Type) {
+				return utils.NewBuildError("Invalid field type, must be a builtin type, a type of the models package or a pointer, slice or map of them", "field", arg)
+			}
+			fields = append(fields, field)
+		}
+		data["Fields"] = fields
+		return generateFiles(c, "model", data)
+	case "test":
+		return generateFiles(c, "test", data)
+	}
+	return utils.NewBuildError("Unknown generator", "generator", c.Generate.Generator)
+}
+
+// Returns true if the type can be used by a field of the generated model. The model has no
+// imports, so types of other packages are refused.
+func isFieldType(typeName string) bool {
+	expr, err := parser.ParseExpr(typeName)
+	if err != nil {
+		return false
+	}
+	var valid func(ast.Expr) bool
+	valid = func(expr ast.Expr) bool {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			return true
+		case *ast.StarExpr:
+			return valid(t.X)
+		case *ast.ArrayType:
+			if _, ok := t.Len.(*ast.BasicLit); t.Len != nil && !ok {
+				return false
+			}
+			return valid(t.Elt)
+		case *ast.MapType:
+			return valid(t.Key) && valid(t.Value)
+		case *ast.InterfaceType:
+			return len(t.Methods.List) == 0
+		}
+		return false
+	}
+	return valid(expr)
+}
+
+// Renders the files of the generator into the application. The file names are rendered using
+// the data, the contents of the ".template" files are rendered by utils.CopyDir.
+func generateFiles(c *model.CommandConfig, generator string, data map[string]interface{}) (err error) {
+	tempDir, err := ioutil.TempDir("", "revel-generate")
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to create temp dir")
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+	srcDir, renderedDir := filepath.Join(tempDir, "src"), filepath.Join(tempDir, "rendered")
+
+	root := "generators/" + generator
+	err = fs.WalkDir(generatorFS, root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		var name bytes.Buffer
+		tmpl, err := template.New("").Parse(strings.TrimPrefix(path, root+"/"))
+		if err == nil {
+			err = tmpl.Execute(&name, data)
+		}
+		if err != nil {
+			return utils.NewBuildIfError(err, "Failed to render file name", "path", path)
+		}
+		content, err := generatorFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		srcPath := filepath.Join(srcDir, filepath.FromSlash(name.String()))
+		if err = os.MkdirAll(filepath.Dir(srcPath), 0777); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(srcPath, content, 0644)
+	})
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to read generator", "generator", generator)
+	}
+	if err = utils.CopyDir(renderedDir, srcDir, data); err != nil {
+		return
+	}
+
+	files := []string{}
+	err = utils.Walk(renderedDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(renderedDir, path)
+		if err == nil && utils.Exists(filepath.Join(c.AppPath, relPath)) {
+			err = utils.NewBuildError("File already exists, refusing to overwrite", "path", filepath.Join(c.AppPath, relPath))
+		}
+		files = append(files, relPath)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	for _, relPath := range files {
+		destPath := filepath.Join(c.AppPath, relPath)
+		if c.Generate.DryRun {
+			fmt.Println("Would create:", destPath)
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(destPath), 0777); err != nil {
+			return utils.NewBuildIfError(err, "Failed to create directory", "path", filepath.Dir(destPath))
+		}
+		if strings.HasSuffix(relPath, ".go") {
+			if err = formatGoFile(filepath.Join(renderedDir, relPath)); err != nil {
+				return
+			}
+		}
+		if err = utils.CopyFile(destPath, filepath.Join(renderedDir, relPath)); err != nil {
+			return
+		}
+		fmt.Println("Created:", destPath)
+	}
+	return
+}
+
+// Formats the go file in place, the rendered fields are not aligned by the templates.
+func formatGoFile(path string) (err error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to read file", "path", path)
+	}
+	formatted, err := format.Source(source)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to format file", "path", path)
+	}
+	return ioutil.WriteFile(path, formatted, 0644)
+}
+
+// Returns a line for the routes file, the path is formatted with the path of the generated name.
+func routeLine(method, pathFormat, action string, data map[string]interface{}) string {
+	return fmt.Sprintf("%-7s %-39s %s", method, fmt.Sprintf(pathFormat, data["Path"]), action)
+}
+
+// Adds the routes to the routes file, routes for actions that are already routed are skipped.
+// The routes are added before the catch all route if there is one, so they take precedence.
+func addGeneratedRoutes(c *model.CommandConfig, lines []string) (err error) {
+	routesPath := filepath.Join(c.AppPath, "conf", "routes")
+	content, err := ioutil.ReadFile(routesPath)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to read routes", "path", routesPath)
+	}
+	existing := model.ParseRoutes("", routesPath, "", string(content))
+
+	added := []string{}
+	for _, line := range lines {
+		newRoute := model.ParseRoutes("", routesPath, "", line)[0]
+		found := false
+		for _, route := range existing {
+			if strings.EqualFold(route.Action, newRoute.Action) {
+				found = true
+				break
+			}
+		}
+		if found {
+			fmt.Println("Route exists, skipping:", newRoute.Action)
+			continue
+		}
+		added = append(added, line)
+		if c.Generate.DryRun {
+			fmt.Println("Would add route:", line)
+		}
+	}
+	if len(added) == 0 || c.Generate.DryRun {
+		return
+	}
+
+	fileLines := strings.Split(string(content), "\n")
+	insertAt := len(fileLines)
+	for _, route := range existing {
+		if strings.HasPrefix(route.ControllerName(), ":") || strings.HasPrefix(route.MethodName(), ":") {
+			insertAt = route.Line - 1
+			// Keep the comments describing the catch all route with it
+			for insertAt > 0 && strings.HasPrefix(strings.TrimSpace(fileLines[insertAt-1]), "#") {
+				insertAt--
+			}
+			break
+		}
+	}
+	block := append(append([]string{""}, added...), "")
+	fileLines = append(fileLines[:insertAt], append(block, fileLines[insertAt:]...)...)
+
+	if err = ioutil.WriteFile(routesPath, []byte(strings.Join(fileLines, "\n")), 0644); err != nil {
+		return utils.NewBuildIfError(err, "Failed to write routes", "path", routesPath)
+	}
+	fmt.Println("Added routes to:", routesPath)
+	return
+}
+
+// Generates the client package.
+func generateClient(c *model.CommandConfig) (err error) {
+	revelPaths, err := model.NewRevelPaths(c.Generate.Mode, c.ImportPath, c.AppPath, model.NewWrappedRevelCallback(nil, c.PackageResolver))
+	if err != nil {
+		return
+	}
+
+	sourceInfo, err := harness.ProcessSource(c, revelPaths)
+	if err != nil {
+		return
+	}
+
+	targetPath := model.FirstNonEmpty(c.Generate.Client.TargetPath, filepath.Join("client", "client.go"))
+	if !filepath.IsAbs(targetPath) {
+		targetPath = filepath.Join(c.AppPath, targetPath)
+	}
+	packageName := c.Generate.Client.PackageName
+	if packageName == "" {
+		packageName = strings.ReplaceAll(filepath.Base(filepath.Dir(targetPath)), "-", "_")
+	}
+
+	if c.Generate.DryRun {
+		fmt.Println("Would create:", targetPath)
+		return
+	}
+	if err = harness.GenerateClient(revelPaths, sourceInfo, targetPath, packageName); err != nil {
+		return
+	}
+
+	fmt.Println("Client written to:", targetPath)
+	return
+}
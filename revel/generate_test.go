@@ -0,0 +1,142 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/revel/cmd/model"
+	main "github.com/revel/cmd/revel"
+	"github.com/revel/cmd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const generateRoutes = `# Routes
+GET     /                                       App.Index
+
+# Catch all
+*       /:controller/:action                    :controller.:action
+`
+
+// Creates an application folder with a routes file and the command config to generate into it.
+func newGenerateApp(t *testing.T, generator string, args ...string) *model.CommandConfig {
+	appPath, err := ioutil.TempDir("", "revel-test-generate")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(appPath) })
+	assert.Nil(t, os.MkdirAll(filepath.Join(appPath, "conf"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(appPath, "conf", "routes"), []byte(generateRoutes), 0644))
+
+	c := &model.CommandConfig{Index: model.GENERATE, AppPath: appPath, ImportPath: "example.com/app", AppName: "app"}
+	c.Generate.Generator = generator
+	c.Generate.Args = args
+	return c
+}
+
+func readGenerated(t *testing.T, c *model.CommandConfig, path ...string) string {
+	content, err := ioutil.ReadFile(filepath.Join(append([]string{c.AppPath}, path...)...))
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func TestGenerateController(t *testing.T) {
+	a := assert.New(t)
+	c := newGenerateApp(t, "controller", "users")
+	a.Nil(main.Commands[model.GENERATE].RunWith(c))
+
+	a.Contains(readGenerated(t, c, "app", "controllers", "users.go"), "type Users struct {")
+	for _, view := range []string{"Index", "Show", "New", "Edit"} {
+		a.True(utils.Exists(filepath.Join(c.AppPath, "app", "views", "Users", view+".html")), view)
+	}
+
+	// The routes are added before the catch all route and its comment
+	routes := readGenerated(t, c, "conf", "routes")
+	a.Contains(routes, "GET     /users/:id                              Users.Show")
+	a.True(strings.Index(routes, "Users.Delete") < strings.Index(routes, "# Catch all"), routes)
+
+	// A second run refuses to overwrite the files and leaves the routes alone
+	a.NotNil(main.Commands[model.GENERATE].RunWith(c))
+	a.Equal(routes, readGenerated(t, c, "conf", "routes"))
+}
+
+func TestGenerateDryRun(t *testing.T) {
+	a := assert.New(t)
+	c := newGenerateApp(t, "controller", "users")
+	c.Generate.DryRun = true
+	a.Nil(main.Commands[model.GENERATE].RunWith(c))
+
+	a.False(utils.Exists(filepath.Join(c.AppPath, "app")))
+	a.Equal(generateRoutes, readGenerated(t, c, "conf", "routes"))
+}
+
+func TestGenerateModel(t *testing.T) {
+	a := assert.New(t)
+	c := newGenerateApp(t, "model", "user", "name", "age:int", "tags:[]string", "friends:map[string]*User")
+	a.Nil(main.Commands[model.GENERATE].RunWith(c))
+
+	source := readGenerated(t, c, "app", "models", "user.go")
+	a.Contains(source, "type User struct {")
+	a.Contains(source, "Name    string           `json:\"name\"`")
+	a.Contains(source, "Age     int              `json:\"age\"`")
+	a.Contains(source, "Tags    []string         `json:\"tags\"`")
+	a.Contains(source, "Friends map[string]*User `json:\"friends\"`")
+}
+
+func TestGenerateModelInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"1user"},
+		{"user", "first-name:string"},
+		{"user", "name:strng)"},
+		{"user", "created:time.Time"},
+		{"user", "count:a+b"},
+		{"user", "items:[n]int"},
+	} {
+		c := newGenerateApp(t, "model", args...)
+		assert.NotNil(t, main.Commands[model.GENERATE].RunWith(c), strings.Join(args, " "))
+		assert.False(t, utils.Exists(filepath.Join(c.AppPath, "app")), strings.Join(args, " "))
+	}
+}
+
+func TestGenerateTest(t *testing.T) {
+	a := assert.New(t)
+	c := newGenerateApp(t, "test", "users")
+	a.Nil(main.Commands[model.GENERATE].RunWith(c))
+
+	source := readGenerated(t, c, "tests", "userstest.go")
+	a.Contains(source, "type UsersTest struct {")
+	a.Contains(source, `t.Get("/users")`)
+	a.NotContains(source, "println")
+}
+
+// Test that the import path is read after the name, or after the fields of the model.
+func TestGenerateArgs(t *testing.T) {
+	wd, _ := os.Getwd()
+	for line, expected := range map[string][]string{
+		"generate model User name:string example.com/app":     {"example.com/app", "User", "name:string"},
+		"generate model User name:string age ../app":          {"../app", "User", "name:string", "age"},
+		"generate model User name:string age":                 {wd, "User", "name:string", "age"},
+		"generate model User":                                 {wd, "User"},
+		"gen controller Users example.com/app":                {"example.com/app", "Users"},
+		"gen test Users app":                                  {"app", "Users"},
+		"gen test -a example.com/flag Users example.com/app":  {"example.com/app", "Users"},
+		"gen test -a example.com/flag Users":                  {"example.com/flag", "Users"},
+		"generate client example.com/app":                     {"example.com/app"},
+		"generate model User name:string age:int example.app": {"example.app", "User", "name:string", "age:int"},
+	} {
+		c := &model.CommandConfig{}
+		if !assert.Nil(t, main.ParseArgs(c, flags.NewParser(c, flags.HelpFlag|flags.PassDoubleDash), strings.Fields(line)), line) {
+			continue
+		}
+		assert.Equal(t, model.GENERATE, c.Index, line)
+		assert.Equal(t, expected[0], c.Generate.ImportPath, line)
+		if len(expected) > 1 {
+			assert.Equal(t, expected[1:], c.Generate.Args, line)
+		}
+	}
+
+	// The generators other than the client require a name
+	c := &model.CommandConfig{}
+	assert.NotNil(t, main.ParseArgs(c, flags.NewParser(c, flags.HelpFlag|flags.PassDoubleDash), []string{"generate", "model"}))
+}
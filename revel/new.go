@@ -5,6 +5,7 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"go/build"
 	"math/rand"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mattn/go-isatty"
 	"github.com/revel/cmd/model"
 	"github.com/revel/cmd/utils"
 )
@@ -21,7 +23,7 @@ import (
 const ErrNoSkeleton Error = "failed to find skeleton in filepath"
 
 var cmdNew = &Command{
-	UsageLine: "new -i [path] -s [skeleton] -p [package name]",
+	UsageLine: "new -i [path] -s [skeleton] -p [package name] [--set key=value]",
 	Short:     "create a skeleton Revel application",
 	Long: `
 New creates a few files to get a new Revel application running quickly.
@@ -29,7 +31,15 @@ New creates a few files to get a new Revel application running quickly.
 It puts all of the files in the given import path, taking the final element in
 the path to be the app name.
 
-Skeleton is an optional argument, provided as an import path
+Skeleton is an optional argument, provided as a path, a git url (a ref may be
+given as the fragment, e.g. #v1.0.0), a .tar.gz or .zip archive, or a Go module
+at a version which is fetched using GOPROXY. A sub folder of the skeleton may be
+selected by appending it after a colon.
+
+A skeleton may declare variables, files included only for some values and
+commands run after the application is created in a skeleton.yaml or
+skeleton.toml file in its root. The values are asked for when running in a
+terminal, or they can be set using --set.
 
 For example:
 
@@ -37,6 +47,14 @@ For example:
 
     revel new -a import/path/helloworld -s import/path/skeleton
 
+    revel new -a import/path/helloworld -s https://github.com/revel/skeletons:basic/bootstrap4#v1.0.0
+
+    revel new -a import/path/helloworld -s github.com/revel/skeletons@latest:basic/bootstrap4
+
+    revel new -a import/path/helloworld -s skeletons.tar.gz:basic/bootstrap4
+
+    revel new -a import/path/helloworld -s import/path/skeleton --set DBDriver=postgres
+
 `,
 }
 
@@ -76,7 +94,12 @@ func newApp(c *model.CommandConfig) (err error) {
 		return utils.NewBuildError("Abort: Import path already exists.", "path", c.ImportPath, "apppath", c.AppPath)
 	}
 
-	// checking and setting skeleton
+	// checking and setting skeleton, skeletons which are fetched are removed when done
+	defer func() {
+		if c.New.SkeletonTempDir != "" {
+			_ = os.RemoveAll(c.New.SkeletonTempDir)
+		}
+	}()
 	if err = setSkeletonPath(c); err != nil {
 		return
 	}
@@ -111,6 +134,11 @@ func newApp(c *model.CommandConfig) (err error) {
 		}
 	}
 
+	// Run the skeleton hooks, now the module exists
+	if err = runPostCreateHooks(c); err != nil {
+		return
+	}
+
 	// goodbye world
 	fmt.Fprintln(os.Stdout, "Your application has been created in:\n  ", c.AppPath)
 	// Check to see if it should be run right off
@@ -199,6 +227,14 @@ func setSkeletonPath(c *model.CommandConfig) (err error) {
 		c.New.SkeletonPath = "https://" + RevelSkeletonsImportPath + ":basic/bootstrap4"
 	}
 
+	// Archives and modules are checked first, their paths are not urls
+	if matches := skeletonArchivePattern.FindStringSubmatch(c.New.SkeletonPath); matches != nil {
+		return newLoadFromArchive(c, matches[1], matches[2])
+	}
+	if matches := skeletonModulePattern.FindStringSubmatch(c.New.SkeletonPath); matches != nil {
+		return newLoadFromModule(c, matches[1], matches[2], matches[3])
+	}
+
 	// First check to see the protocol of the string
 	sp, err := url.Parse(c.New.SkeletonPath)
 	if err == nil {
@@ -233,37 +269,40 @@ func setSkeletonPath(c *model.CommandConfig) (err error) {
 		default:
 			utils.Logger.Fatal("Unsupported skeleton schema ", "path", c.New.SkeletonPath)
 		}
-		// TODO check to see if the path needs to be extracted
 	} else {
 		utils.Logger.Fatal("Invalid skeleton path format", "path", c.New.SkeletonPath)
 	}
 	return
 }
 
-// Load skeleton from git.
+// Load skeleton from git, the url fragment may name the ref to check out.
 func newLoadFromGit(c *model.CommandConfig, sp *url.URL) (err error) {
 	// This method indicates we need to fetch from a repository using git
 	// Execute "git clone get <pkg>"
-	targetPath := filepath.Join(os.TempDir(), "revel", "skeleton")
-	os.RemoveAll(targetPath)
+	targetPath, err := newSkeletonTempDir(c)
+	if err != nil {
+		return
+	}
 	pathpart := strings.Split(sp.Path, ":")
 	getCmd := exec.Command("git", "clone", sp.Scheme+"://"+sp.Host+pathpart[0], targetPath)
 	utils.Logger.Info("Exec:", "args", getCmd.Args)
 	getOutput, err := getCmd.CombinedOutput()
 	if err != nil {
-		utils.Logger.Fatal("Abort: could not clone the  Skeleton  source code: ", "output", string(getOutput), "path", c.New.SkeletonPath)
+		return utils.NewBuildIfError(err, "Could not clone the skeleton source code", "output", string(getOutput), "path", c.New.SkeletonPath)
 	}
-	outputPath := targetPath
-	if len(pathpart) > 1 {
-		outputPath = filepath.Join(targetPath, filepath.Join(strings.Split(pathpart[1], string('/'))...))
-	}
-	outputPath, _ = filepath.Abs(outputPath)
-	if !strings.HasPrefix(outputPath, targetPath) {
-		utils.Logger.Fatal("Unusual target path outside root path", "target", outputPath, "root", targetPath)
+	if sp.Fragment != "" {
+		checkoutCmd := exec.Command("git", "-C", targetPath, "checkout", "--quiet", sp.Fragment)
+		utils.Logger.Info("Exec:", "args", checkoutCmd.Args)
+		if checkoutOutput, err := checkoutCmd.CombinedOutput(); err != nil {
+			return utils.NewBuildIfError(err, "Could not check out the skeleton ref", "output", string(checkoutOutput), "ref", sp.Fragment)
+		}
 	}
 
-	c.New.SkeletonPath = outputPath
-	return
+	subPath := ""
+	if len(pathpart) > 1 {
+		subPath = pathpart[1]
+	}
+	return setSkeletonSubPath(c, targetPath, subPath)
 }
 
 func copyNewAppFiles(c *model.CommandConfig) (err error) {
@@ -272,18 +311,116 @@ func copyNewAppFiles(c *model.CommandConfig) (err error) {
 		return utils.NewBuildIfError(err, "MKDIR failed")
 	}
 
-	err = utils.CopyDir(c.AppPath, c.New.SkeletonPath, map[string]interface{}{
+	data := map[string]interface{}{
 		// app.conf
 		"AppName":  c.AppName,
 		"BasePath": c.AppPath,
 		"Secret":   generateSecret(),
-	})
+	}
+	manifest, err := model.LoadSkeletonManifest(c.New.SkeletonPath)
+	if err != nil {
+		return
+	}
+	if manifest != nil {
+		if err = setSkeletonValues(c, manifest, data); err != nil {
+			return
+		}
+	}
+
+	// The files the skeleton manifest excludes for the values are not copied
+	var excluded func(relPath string) (bool, error)
+	if manifest != nil {
+		excluded = func(relPath string) (bool, error) {
+			return manifest.Excluded(relPath, data)
+		}
+	}
+	err = utils.CopyDirFiltered(c.AppPath, c.New.SkeletonPath, data, excluded)
 	if err != nil {
 		fmt.Printf("err %v", err)
 		return utils.NewBuildIfError(err, "Copy Dir failed")
 	}
 
+	if manifest != nil {
+		if c.New.PostCreate, err = manifest.PostCreateHooks(data); err != nil {
+			return
+		}
+	}
+
 	// Dotfiles are skipped by mustCopyDir, so we have to explicitly copy the .gitignore.
 	gitignore := ".gitignore"
+	if excluded != nil {
+		if skip, err := excluded(gitignore); err != nil || skip {
+			return err
+		}
+	}
 	return utils.CopyFile(filepath.Join(c.AppPath, gitignore), filepath.Join(c.New.SkeletonPath, gitignore))
 }
+
+// Sets the values of the skeleton variables in the data. The values are taken from the --set
+// options, the variables without one are prompted for when running in a terminal.
+func setSkeletonValues(c *model.CommandConfig, manifest *model.SkeletonManifest, data map[string]interface{}) (err error) {
+	values := map[string]string{}
+	for _, set := range c.New.Set {
+		parts := strings.SplitN(set, "=", 2)
+		if len(parts) != 2 {
+			return utils.NewBuildError("Invalid --set, expected key=value", "set", set)
+		}
+		values[strings.TrimSpace(parts[0])] = parts[1]
+	}
+
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		if manifest.Description != "" {
+			fmt.Println(manifest.Description)
+		}
+		reader := bufio.NewReader(os.Stdin)
+		for _, variable := range manifest.Variables {
+			if _, found := values[variable.Name]; !found {
+				values[variable.Name] = promptSkeletonVariable(reader, variable)
+			}
+		}
+	}
+
+	if err = manifest.Values(values, data); err != nil {
+		return utils.NewBuildIfError(err, "Invalid skeleton values", "manifest", manifest.Path)
+	}
+	return
+}
+
+// Asks for the value of the variable until a valid one is entered, the default is used for an empty answer.
+func promptSkeletonVariable(reader *bufio.Reader, variable *model.SkeletonVariable) string {
+	prompt := model.FirstNonEmpty(variable.Prompt, variable.Name)
+	if len(variable.Choices) > 0 {
+		prompt += " (" + strings.Join(variable.Choices, ", ") + ")"
+	}
+	for {
+		fmt.Printf("%s [%s]: ", prompt, variable.Default)
+		line, err := reader.ReadString('\n')
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = variable.Default
+		}
+		if err != nil {
+			// End of input, validation is left to the caller
+			fmt.Println()
+			return value
+		}
+		if _, err = variable.Parse(value); err == nil {
+			return value
+		}
+		fmt.Println(err)
+	}
+}
+
+// Runs the post create hooks of the skeleton in the application folder.
+func runPostCreateHooks(c *model.CommandConfig) (err error) {
+	for _, hook := range c.New.PostCreate {
+		hookCmd := utils.ShellCmd(hook)
+		utils.CmdInit(hookCmd, !c.Vendored, c.AppPath)
+		utils.Logger.Info("Exec:", "args", hookCmd.Args, "workingdir", hookCmd.Dir)
+		fmt.Fprintln(os.Stdout, "Running:", hook)
+		if output, err := hookCmd.CombinedOutput(); err != nil {
+			return utils.NewBuildIfError(err, "Skeleton hook failed", "hook", hook, "output", string(output))
+		}
+	}
+	return
+}
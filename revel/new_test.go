@@ -1,11 +1,14 @@
 package main_test
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/revel/cmd/model"
 	main "github.com/revel/cmd/revel"
+	"github.com/revel/cmd/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,6 +50,23 @@ func TestNew(t *testing.T) {
 		c.New.SkeletonPath = "git://github.com/revel/skeletons:basic/bootstrap4"
 		a.Nil(main.Commands[model.NEW].RunWith(c), "Failed to run with new skeleton git")
 	})
+	t.Run("Skeleton-Archive", func(t *testing.T) {
+		a := assert.New(t)
+		skeletonPath := filepath.Join(gopath, "skeleton", "basic")
+		a.Nil(os.MkdirAll(filepath.Join(skeletonPath, "conf"), 0777))
+		a.Nil(ioutil.WriteFile(filepath.Join(skeletonPath, "conf", "app.conf.template"), []byte("app.name={{.AppName}}\n"), 0644))
+		a.Nil(ioutil.WriteFile(filepath.Join(skeletonPath, ".gitignore"), []byte("target\n"), 0644))
+		archivePath, err := utils.TarGzDir(filepath.Join(gopath, "skeleton.tar.gz"), filepath.Join(gopath, "skeleton"))
+		a.Nil(err)
+
+		c := newApp("new/test/d/1", model.NEW, nil, a)
+		c.New.SkeletonPath = archivePath + ":missing"
+		a.NotNil(main.Commands[model.NEW].RunWith(c), "Expected missing sub folder to fail")
+		c = newApp("new/test/d/2", model.NEW, nil, a)
+		c.New.SkeletonPath = archivePath + ":basic"
+		a.Nil(main.Commands[model.NEW].RunWith(c), "Failed to run with new skeleton archive")
+		a.True(utils.Exists(filepath.Join(c.AppPath, "conf", "app.conf")), "Expected skeleton to be copied")
+	})
 	if !t.Failed() {
 		if err := os.RemoveAll(gopath); err != nil {
 			a.Fail("Failed to remove test path")
@@ -0,0 +1,106 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"gopkg.in/yaml.v3"
+)
+
+var cmdOpenAPI = &Command{
+	UsageLine: "openapi [-m [run mode]] [-t [target path]] [import path]",
+	Short:     "generate an OpenAPI 3 document for a Revel application",
+	Long: `
+Generates an OpenAPI 3 document from the routes file and the controllers of
+the Revel application named by the given import path.
+
+Action arguments are mapped to path, query or request body parameters, struct
+arguments defined in the application are described as schemas and the doc
+comment of the action is used as its summary.
+
+The format is taken from the extension of the target path (.json, .yaml) unless
+it is specified.
+
+For example:
+
+    revel openapi -a github.com/revel/examples/booking -t booking.json
+`,
+}
+
+func init() {
+	cmdOpenAPI.RunWith = openAPIApp
+	cmdOpenAPI.UpdateConfig = updateOpenAPIConfig
+}
+
+// Update the openapi command configuration.
+func updateOpenAPIConfig(c *model.CommandConfig, args []string) bool {
+	c.Index = model.OPENAPI
+	if len(args) > 0 {
+		c.OpenAPI.ImportPath = args[0]
+	}
+	if c.OpenAPI.ImportPath == "" {
+		c.OpenAPI.ImportPath, _ = os.Getwd()
+	}
+	return true
+}
+
+// Called to generate the OpenAPI document.
+func openAPIApp(c *model.CommandConfig) (err error) {
+	revelPaths, err := model.NewRevelPaths(c.OpenAPI.Mode, c.ImportPath, c.AppPath, model.NewWrappedRevelCallback(nil, c.PackageResolver))
+	if err != nil {
+		return
+	}
+
+	sourceInfo, err := harness.ProcessSource(c, revelPaths)
+	if err != nil {
+		return
+	}
+
+	doc, err := harness.GenerateOpenAPI(revelPaths, sourceInfo)
+	if err != nil {
+		return
+	}
+
+	targetPath := c.OpenAPI.TargetPath
+	if targetPath == "" {
+		targetPath = "openapi." + model.FirstNonEmpty(c.OpenAPI.Format, "yaml")
+	}
+	if !filepath.IsAbs(targetPath) {
+		targetPath = filepath.Join(c.AppPath, targetPath)
+	}
+	format := c.OpenAPI.Format
+	if format == "" {
+		format = "yaml"
+		if strings.ToLower(filepath.Ext(targetPath)) == ".json" {
+			format = "json"
+		}
+	}
+
+	var output []byte
+	if format == "json" {
+		output, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		output, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to encode OpenAPI document")
+	}
+
+	if err = ioutil.WriteFile(targetPath, output, 0644); err != nil {
+		return utils.NewBuildIfError(err, "Failed to write OpenAPI document", "path", targetPath)
+	}
+
+	fmt.Println("OpenAPI document written to:", targetPath)
+	return
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/revel/cmd/harness"
 	"github.com/revel/cmd/model"
 	"github.com/revel/cmd/utils"
 )
@@ -82,6 +83,9 @@ func packageApp(c *model.CommandConfig) (err error) {
 	c.Build.Mode = c.Package.Mode
 	c.Build.TargetPath = tmpDir
 	c.Build.CopySource = c.Package.CopySource
+	if err = harness.RunHooks(revelPaths, model.PACKAGE_STARTED, revelPaths); err != nil {
+		return
+	}
 	if err = buildApp(c); err != nil {
 		return
 	}
@@ -92,6 +96,9 @@ func packageApp(c *model.CommandConfig) (err error) {
 	if err != nil {
 		return
 	}
+	if err = harness.RunHooks(revelPaths, model.PACKAGE_CREATED, archiveName); err != nil {
+		return
+	}
 
 	fmt.Println("Your archive is ready:", archiveName)
 	return
@@ -0,0 +1,158 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/revel/cmd/harness"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/revel/config"
+)
+
+const ErrProfileFailed Error = "failed to capture the profile"
+
+// The profiles which can be captured, by name. The cpu profile and the trace run for a duration.
+var profileEndpoints = map[string]string{
+	"cpu":          "profile",
+	"trace":        "trace",
+	"heap":         "heap",
+	"allocs":       "allocs",
+	"goroutine":    "goroutine",
+	"block":        "block",
+	"mutex":        "mutex",
+	"threadcreate": "threadcreate",
+}
+
+var cmdProfile = &Command{
+	UsageLine: "profile <cpu|heap|allocs|goroutine|block|mutex|threadcreate|trace> [--seconds 30] [import path]",
+	Short:     "capture a profile of an application started with revel run --pprof",
+	Long: `
+Captures a profile of the application through the harness proxy and saves it
+to a file. The application must be running with revel run --pprof, which
+serves the net/http/pprof endpoints of the application under /@harness/pprof/
+without any changes to the application code.
+
+For example, to capture 30 seconds of cpu usage and then the heap:
+
+    revel run --pprof github.com/revel/examples/booking
+    revel profile cpu --seconds 30 github.com/revel/examples/booking
+    revel profile heap -o heap.pprof github.com/revel/examples/booking
+
+The proxy address is read from the app.conf of the application, use --url if
+the proxy listens somewhere else (e.g. revel run -p 8080):
+
+    revel profile cpu --url http://localhost:8080
+
+The profile is read with go tool pprof, the trace with go tool trace.
+`,
+}
+
+func init() {
+	cmdProfile.RunWith = profileApp
+	cmdProfile.UpdateConfig = updateProfileConfig
+}
+
+// Update the profile command configuration.
+func updateProfileConfig(c *model.CommandConfig, args []string) bool {
+	c.Index = model.PROFILE
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "A profile is required:", cmdProfile.UsageLine)
+		return false
+	}
+	if _, found := profileEndpoints[args[0]]; !found {
+		fmt.Fprintln(os.Stderr, "Unknown profile", args[0]+":", cmdProfile.UsageLine)
+		return false
+	}
+	c.Profile.Name = args[0]
+	if len(args) > 1 {
+		c.Profile.ImportPath = args[1]
+	}
+	return true
+}
+
+// Fetches the profile from the harness proxy and saves it.
+func profileApp(c *model.CommandConfig) (err error) {
+	baseURL := c.Profile.URL
+	if baseURL == "" {
+		if baseURL, err = profileProxyURL(c); err != nil {
+			return
+		}
+	}
+
+	endpoint := profileEndpoints[c.Profile.Name]
+	if endpoint == "profile" || endpoint == "trace" {
+		endpoint += "?seconds=" + strconv.Itoa(c.Profile.Seconds)
+	}
+	profileURL := strings.TrimRight(baseURL, "/") + harness.PprofPath + endpoint
+
+	output := c.Profile.Output
+	if output == "" {
+		extension := ".pprof"
+		if c.Profile.Name == "trace" {
+			extension = ".out"
+		}
+		output = c.Profile.Name + "-" + time.Now().Format("20060102-150405") + extension
+	}
+
+	fmt.Printf("Capturing the %s profile from %s\n", c.Profile.Name, profileURL)
+	client := &http.Client{
+		Timeout: time.Duration(c.Profile.Seconds)*time.Second + 30*time.Second,
+		// The proxy of the harness may use a self signed certificate
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	response, err := client.Get(profileURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v (is the application running with revel run --pprof?)", ErrProfileFailed, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("%w: %s %s", ErrProfileFailed, response.Status, strings.TrimSpace(string(body)))
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Failed to create profile", "path", output)
+	}
+	defer file.Close()
+	if _, err = io.Copy(file, response.Body); err != nil {
+		return utils.NewBuildIfError(err, "Failed to write profile", "path", output)
+	}
+
+	tool := "go tool pprof -http=localhost:0 "
+	if c.Profile.Name == "trace" {
+		tool = "go tool trace "
+	}
+	fmt.Printf("Saved the %s profile to %s, view it with:\n\n    %s%s\n", c.Profile.Name, output, tool, output)
+	return
+}
+
+// Returns the URL of the harness proxy from the app.conf of the application.
+func profileProxyURL(c *model.CommandConfig) (string, error) {
+	conf, err := config.LoadContext("app.conf", []string{filepath.Join(c.AppPath, "conf")})
+	if err != nil {
+		return "", utils.NewBuildIfError(err, "Failed to read app.conf, use --url to set the proxy address", "path", c.AppPath)
+	}
+	conf.SetSection(model.FirstNonEmpty(c.Profile.Mode, DefaultRunMode))
+
+	scheme := "http"
+	if conf.BoolDefault("http.ssl", false) {
+		scheme = "https"
+	}
+	host := model.FirstNonEmpty(conf.StringDefault("http.addr", ""), "localhost")
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(conf.IntDefault("http.port", 9000))), nil
+}
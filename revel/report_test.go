@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/revel/cmd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateReports = flag.Bool("update", false, "update the golden report files in testdata/report")
+
+// The results of two suites, the second with a failing test.
+var reportResults = []tests.TestSuiteResult{
+	{Name: "AppTest", Passed: true, Results: []tests.TestResult{
+		{Name: "TestIndex", Passed: true},
+		{Name: "TestAbout", Passed: true},
+	}},
+	{Name: "UserTest", Results: []tests.TestResult{
+		{Name: "TestLogin", Passed: true},
+		{Name: "TestLogout", ErrorHTML: "<p>Expected &#34;/&#34;</p>", ErrorSummary: `Expected "/" & got "/login"`},
+	}},
+}
+
+// Test the reports against the golden files in testdata/report, run with -update to rewrite them.
+func TestReports(t *testing.T) {
+	resultPath, err := ioutil.TempDir("", "revel-report")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(resultPath)
+
+	writeJSONReport(resultPath, reportResults)
+	writeJUnitReport(resultPath, reportResults, []time.Duration{1500 * time.Millisecond, 25 * time.Millisecond})
+
+	for _, name := range []string{"results.json", "junit.xml"} {
+		content, err := ioutil.ReadFile(filepath.Join(resultPath, name))
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		golden := filepath.Join("testdata", "report", name)
+		if *updateReports {
+			assert.Nil(t, ioutil.WriteFile(golden, content, 0644))
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		assert.Nil(t, err, name)
+		assert.Equal(t, string(expected), string(content), name)
+	}
+}
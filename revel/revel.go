@@ -7,7 +7,7 @@ package main
 
 import (
 	"bytes"
-	"flag"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -30,7 +30,10 @@ func (e Error) Error() string {
 	return string(e)
 }
 
-const ErrInvalidCommandLine Error = "invalid command line arguments"
+const (
+	ErrInvalidCommandLine   Error = "invalid command line arguments"
+	ErrInvalidProjectConfig Error = "invalid configuration in"
+)
 
 const (
 	// RevelCmdImportPath Revel framework cmd tool import path.
@@ -67,6 +70,11 @@ var Commands = []*Command{
 	cmdClean,
 	cmdTest,
 	cmdVersion,
+	cmdOpenAPI,
+	cmdGenerate,
+	cmdDoctor,
+	cmdConfig,
+	cmdProfile,
 }
 
 func main() {
@@ -110,39 +118,63 @@ func main() {
 	println("Revel executing:", command.Short)
 
 	if err := command.RunWith(c); err != nil {
-		utils.Logger.Error("Unable to execute", "error", err)
+		if !printSourceError(err) {
+			utils.Logger.Error("Unable to execute", "error", err)
+		}
 		os.Exit(1)
 	}
 }
 
+// Prints the error with its source if it is a compile error, returns false for other errors.
+func printSourceError(err error) bool {
+	var sourceError *utils.SourceError
+	if !errors.As(err, &sourceError) {
+		return false
+	}
+	fmt.Println(sourceError.TerminalString())
+	return true
+}
+
 // Parse the arguments passed into the model.CommandConfig.
 func ParseArgs(c *model.CommandConfig, parser *flags.Parser, args []string) (err error) {
-	var extraArgs []string
-	if ini := flag.String("ini", "none", ""); *ini != "none" {
-		if err = flags.NewIniParser(parser).ParseFile(*ini); err != nil {
-			return
-		}
-	} else {
-		if extraArgs, err = parser.ParseArgs(args); err != nil {
-			return
-		}
+	// The configuration files are applied first so the command line overrides them
+	if err = applyProjectConfig(c, parser, args); err != nil {
+		return
+	}
+
+	extraArgs, err := parser.ParseArgs(args)
+	if err != nil {
+		return
+	}
 
-		switch parser.Active.Name {
-		case "new":
-			c.Index = model.NEW
-		case "run":
-			c.Index = model.RUN
-		case "build":
-			c.Index = model.BUILD
-		case "package":
-			c.Index = model.PACKAGE
-		case "clean":
-			c.Index = model.CLEAN
-		case "test":
-			c.Index = model.TEST
-		case "version":
-			c.Index = model.VERSION
+	switch parser.Active.Name {
+	case "new":
+		c.Index = model.NEW
+	case "run":
+		c.Index = model.RUN
+	case "build":
+		c.Index = model.BUILD
+	case "package":
+		c.Index = model.PACKAGE
+	case "clean":
+		c.Index = model.CLEAN
+	case "test":
+		c.Index = model.TEST
+	case "version":
+		c.Index = model.VERSION
+	case "openapi":
+		c.Index = model.OPENAPI
+	case "generate":
+		c.Index = model.GENERATE
+		if parser.Active.Active != nil {
+			c.Generate.Generator = parser.Active.Active.Name
 		}
+	case "doctor":
+		c.Index = model.DOCTOR
+	case "config":
+		c.Index = model.CONFIG
+	case "profile":
+		c.Index = model.PROFILE
 	}
 
 	if !Commands[c.Index].UpdateConfig(c, extraArgs) {
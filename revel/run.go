@@ -7,8 +7,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/revel/cmd/harness"
 	"github.com/revel/cmd/model"
@@ -16,7 +18,7 @@ import (
 )
 
 var cmdRun = &Command{
-	UsageLine: "run [-m [run mode] -p [port]] [import path] ",
+	UsageLine: "run [-m [run mode] -p [port]] [import path...] ",
 	Short:     "run a Revel application",
 	Long: `
 Run the Revel web application named by the given import path.
@@ -32,9 +34,26 @@ Run mode defaults to "dev".
 
 You can set a port as well.  For example:
 
-    revel run -m prod -p 8080 github.com/revel/examples/chat `,
+    revel run -m prod -p 8080 github.com/revel/examples/chat
+
+Several applications can be run behind one proxy, routed by path prefix or
+host name. An application without a route is served under the name of its
+folder:
+
+    revel run ./api=/api ./admin=admin.localhost ./web=/
+
+The --delve flag was named --debug before, it was renamed because it
+collided with the global -v/--debug flag.
+
+The harness options of app.conf (harness.rebuild.page, error.link, the
+hooks...) are listed in the README.`,
 }
 
+const (
+	ErrRunAppsNoProxy  Error = "--no-proxy cannot be used with several applications"
+	ErrRunHTTPSNoProxy Error = "--https is served by the proxy, it cannot be used with --no-proxy"
+)
+
 func init() {
 	cmdRun.RunWith = runApp
 	cmdRun.UpdateConfig = updateRunConfig
@@ -51,6 +70,17 @@ func updateRunConfig(c *model.CommandConfig, args []string) bool {
 		}
 		return 0
 	}
+	// Several applications, or one with a route, are run behind one proxy
+	if apps := runAppArgs(args); len(apps) > 1 || (len(apps) == 1 && strings.Contains(apps[0], "=")) {
+		c.Run.Apps = append(c.Run.Apps, apps...)
+		args = args[len(apps):]
+	}
+	if len(c.Run.Apps) > 0 && c.Run.ImportPath == "" {
+		if route, err := model.ParseAppRoute(c.Run.Apps[0]); err == nil {
+			c.Run.ImportPath = route.Path
+		}
+	}
+
 	switch len(args) {
 	case 3:
 		// Possible combinations
@@ -111,11 +141,34 @@ func runIsImportPath(pathToCheck string) bool {
 	return utils.DirExists(pathToCheck)
 }
 
+// Returns the leading arguments which are application folders, optionally with a route.
+func runAppArgs(args []string) (apps []string) {
+	for _, arg := range args {
+		route, err := model.ParseAppRoute(arg)
+		if err != nil || !runIsImportPath(route.Path) {
+			break
+		}
+		apps = append(apps, arg)
+	}
+	return
+}
+
 // Called to run the app.
 func runApp(c *model.CommandConfig) (err error) {
 	if c.Run.Mode == "" {
 		c.Run.Mode = "dev"
 	}
+	if c.Run.Delve {
+		if _, err = harness.FindDebugger(); err != nil {
+			return
+		}
+	}
+	if c.Run.HTTPS && c.Run.NoProxy {
+		return ErrRunHTTPSNoProxy
+	}
+	if len(c.Run.Apps) > 0 {
+		return runApps(c)
+	}
 
 	revelPath, err := model.NewRevelPaths(c.Run.Mode, c.ImportPath, c.AppPath, model.NewWrappedRevelCallback(nil, c.PackageResolver))
 	if err != nil {
@@ -138,6 +191,11 @@ func runApp(c *model.CommandConfig) (err error) {
 		if c.HistoricMode {
 			runMode = revelPath.RunMode
 		}
+		if c.Run.HTTPS {
+			if err = useDevCertificate(revelPath); err != nil {
+				return
+			}
+		}
 		// **** Never returns.
 		harness.NewHarness(c, revelPath, runMode, c.Run.NoProxy).Run()
 	}
@@ -149,6 +207,16 @@ func runApp(c *model.CommandConfig) (err error) {
 		utils.Logger.Errorf("Failed to build app: %s", err)
 	}
 	app.Port = revelPath.HTTPPort
+	if c.Run.Delve {
+		if err = app.Debug(c.Run.DelveAddr); err != nil {
+			return
+		}
+	}
+	if c.Run.Cover {
+		if err = app.Cover(); err != nil {
+			return
+		}
+	}
 	var paths []byte
 	if len(app.PackagePathMap) > 0 {
 		paths, _ = json.Marshal(app.PackagePathMap)
@@ -160,3 +228,100 @@ func runApp(c *model.CommandConfig) (err error) {
 	app.Cmd(runMode).Run(c)
 	return
 }
+
+// Called to run several applications behind one proxy.
+func runApps(c *model.CommandConfig) (err error) {
+	if c.Run.NoProxy {
+		return ErrRunAppsNoProxy
+	}
+	routes, err := model.ParseAppRoutes(c.Run.Apps)
+	if err != nil {
+		return
+	}
+
+	var proxy *harness.MultiHarness
+	var proxyPaths *model.RevelContainer
+	harnesses := map[string]*harness.Harness{}
+	for _, route := range routes {
+		// An application may be reached by more than one route
+		if h, found := harnesses[route.Path]; found {
+			proxy.Add(route, h)
+			continue
+		}
+		app, err := c.AppConfig(route)
+		if err != nil {
+			return utils.NewBuildIfError(err, "Application", "path", route.Path)
+		}
+		revelPath, err := model.NewRevelPaths(c.Run.Mode, app.ImportPath, app.AppPath, model.NewWrappedRevelCallback(nil, app.PackageResolver))
+		if err != nil {
+			return utils.NewBuildIfError(err, "Revel paths", "path", route.Path)
+		}
+
+		if proxy == nil {
+			if c.Run.Port > -1 {
+				revelPath.HTTPPort = c.Run.Port
+			}
+			proxyPaths = revelPath
+			if c.Run.HTTPS {
+				if err = useDevCertificate(proxyPaths); err != nil {
+					return err
+				}
+			}
+			proxy = harness.NewMultiHarness(proxyPaths)
+		} else {
+			// The messages of every application point at the proxy
+			revelPath.HTTPAddr, revelPath.HTTPPort = proxyPaths.HTTPAddr, proxyPaths.HTTPPort
+		}
+		app.Run.Port = proxyPaths.HTTPPort
+		app.Run.DelveAddr = nextDebugAddr(c.Run.DelveAddr, len(harnesses))
+
+		utils.Logger.Infof("Running %s (%s) in %s mode on %s\n", revelPath.AppName, revelPath.ImportPath, revelPath.RunMode, route)
+		runMode := fmt.Sprintf(`{"mode":"%s", "specialUseFlag":%v}`, revelPath.RunMode, c.GetVerbose())
+		if c.HistoricMode {
+			runMode = revelPath.RunMode
+		}
+		harnesses[route.Path] = harness.NewHarness(app, revelPath, runMode, false)
+		proxy.Add(route, harnesses[route.Path])
+	}
+
+	// **** Never returns.
+	proxy.Run()
+	return
+}
+
+// Serves the proxy with the development certificate, the commands to trust the CA are printed
+// when it is new.
+func useDevCertificate(paths *model.RevelContainer) error {
+	var hosts []string
+	if paths.HTTPAddr != "" {
+		hosts = append(hosts, paths.HTTPAddr)
+	}
+	cert, err := harness.LoadDevCertificate(hosts...)
+	if err != nil {
+		return err
+	}
+	paths.ProxySslCert, paths.ProxySslKey = cert.CertFile, cert.KeyFile
+
+	host := model.FirstNonEmpty(paths.HTTPAddr, "localhost")
+	fmt.Printf("The proxy serves https://%s with the development certificate %s\n",
+		net.JoinHostPort(host, strconv.Itoa(paths.HTTPPort)), cert.CertFile)
+	if cert.CACreated {
+		fmt.Printf("A development CA was created, trust it so the browser accepts the certificate:\n\n%s\n", cert.TrustInstructions())
+	} else {
+		fmt.Printf("The certificate is signed by the development CA %s\n", cert.CAFile)
+	}
+	return nil
+}
+
+// Returns the debugger address moved by the number of ports, so every application has its own.
+func nextDebugAddr(addr string, ports int) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort(host, strconv.Itoa(number+ports))
+}
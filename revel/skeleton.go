@@ -0,0 +1,194 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"golang.org/x/mod/module"
+)
+
+const ErrSkeletonNotInProxy Error = "skeleton module not found in any proxy"
+
+var (
+	// Matches a local archive with an optional sub path, e.g. "skeletons.tar.gz:basic/bootstrap4".
+	skeletonArchivePattern = regexp.MustCompile(`^(?:file://)?((?:[a-zA-Z]:)?[^:]+\.(?:tar\.gz|tgz|zip))(?::(.*))?$`)
+
+	// Matches a module at a version with an optional sub path, e.g. "github.com/revel/skeletons@v1.0.0:basic/bootstrap4".
+	skeletonModulePattern = regexp.MustCompile(`^([a-zA-Z0-9\-]+\.[a-zA-Z0-9.\-]+(?:/[^@:]+)*)@([^:]+)(?::(.*))?$`)
+)
+
+// Creates a unique folder to fetch the skeleton into, it is removed once the application is created.
+func newSkeletonTempDir(c *model.CommandConfig) (path string, err error) {
+	if path, err = ioutil.TempDir("", "revel-skeleton-"); err != nil {
+		return "", utils.NewBuildIfError(err, "Failed to create temp dir for skeleton")
+	}
+	c.New.SkeletonTempDir = path
+	return
+}
+
+// Sets the skeleton path to the sub path of the fetched skeleton.
+func setSkeletonSubPath(c *model.CommandConfig, rootPath, subPath string) (err error) {
+	outputPath := rootPath
+	if len(subPath) > 0 {
+		outputPath = filepath.Join(rootPath, filepath.Join(strings.Split(subPath, "/")...))
+	}
+	outputPath, _ = filepath.Abs(outputPath)
+	if !strings.HasPrefix(outputPath, rootPath) {
+		return utils.NewBuildError("Unusual target path outside root path", "target", outputPath, "root", rootPath)
+	}
+	if !utils.DirExists(outputPath) {
+		return fmt.Errorf("%w %s %s", ErrNoSkeleton, outputPath, c.New.SkeletonPath)
+	}
+
+	c.New.SkeletonPath = outputPath
+	return
+}
+
+// Load skeleton from a .tar.gz or .zip archive. If the archive contains a single folder
+// (like the archives of a repository) and the sub path is not found, the sub path is
+// relative to that folder.
+func newLoadFromArchive(c *model.CommandConfig, archivePath, subPath string) (err error) {
+	if archivePath, err = filepath.Abs(archivePath); err != nil {
+		return
+	}
+	if !utils.Exists(archivePath) {
+		return fmt.Errorf("%w %s", ErrNoSkeleton, archivePath)
+	}
+	targetPath, err := newSkeletonTempDir(c)
+	if err != nil {
+		return
+	}
+	utils.Logger.Info("Extracting skeleton", "archive", archivePath, "target", targetPath)
+	if err = utils.ExtractArchive(targetPath, archivePath); err != nil {
+		return
+	}
+
+	rootPath := targetPath
+	entries, err := ioutil.ReadDir(targetPath)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() && (subPath == "" || !utils.DirExists(filepath.Join(targetPath, subPath))) {
+		rootPath = filepath.Join(targetPath, entries[0].Name())
+	}
+	return setSkeletonSubPath(c, rootPath, subPath)
+}
+
+// Load skeleton from a Go module proxy, the proxies are read from GOPROXY. Use "latest" as
+// the version for the latest release.
+func newLoadFromModule(c *model.CommandConfig, modulePath, version, subPath string) (err error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return utils.NewBuildIfError(err, "Invalid skeleton module path", "path", modulePath)
+	}
+
+	for _, proxy := range goProxies(c) {
+		moduleURL := strings.TrimSuffix(proxy, "/") + "/" + escapedPath
+		resolved, err := resolveModuleVersion(moduleURL, version)
+		if err != nil {
+			utils.Logger.Info("Skeleton module not found in proxy", "proxy", proxy, "module", modulePath, "error", err)
+			continue
+		}
+		escapedVersion, err := module.EscapeVersion(resolved)
+		if err != nil {
+			return utils.NewBuildIfError(err, "Invalid skeleton module version", "version", resolved)
+		}
+		zipContent, err := fetchFromProxy(moduleURL + "/@v/" + escapedVersion + ".zip")
+		if err != nil {
+			utils.Logger.Info("Skeleton module not found in proxy", "proxy", proxy, "module", modulePath, "error", err)
+			continue
+		}
+
+		targetPath, err := newSkeletonTempDir(c)
+		if err != nil {
+			return err
+		}
+		zipPath := filepath.Join(targetPath, "module.zip")
+		if err = ioutil.WriteFile(zipPath, zipContent, 0600); err != nil {
+			return utils.NewBuildIfError(err, "Failed to write skeleton module", "path", zipPath)
+		}
+		extractPath := filepath.Join(targetPath, "src")
+		if err = utils.ExtractArchive(extractPath, zipPath); err != nil {
+			return err
+		}
+		utils.Logger.Info("Fetched skeleton module", "proxy", proxy, "module", modulePath, "version", resolved)
+
+		// Module zip files contain the files in a "module@version" folder
+		return setSkeletonSubPath(c, filepath.Join(extractPath, filepath.FromSlash(modulePath)+"@"+resolved), subPath)
+	}
+	return fmt.Errorf("%w: %s@%s", ErrSkeletonNotInProxy, modulePath, version)
+}
+
+// Returns the canonical version of the module from the proxy.
+func resolveModuleVersion(moduleURL, version string) (string, error) {
+	infoURL := moduleURL + "/@latest"
+	if version != "latest" {
+		escapedVersion, err := module.EscapeVersion(version)
+		if err != nil {
+			return "", err
+		}
+		infoURL = moduleURL + "/@v/" + escapedVersion + ".info"
+	}
+	content, err := fetchFromProxy(infoURL)
+	if err != nil {
+		return "", err
+	}
+	info := struct{ Version string }{}
+	if err = json.Unmarshal(content, &info); err != nil || info.Version == "" {
+		return "", fmt.Errorf("invalid version info from %s: %v", infoURL, err)
+	}
+	return info.Version, nil
+}
+
+// Reads the url from the proxy, "file://" urls are read from disk.
+func fetchFromProxy(rawURL string) ([]byte, error) {
+	if strings.HasPrefix(rawURL, "file://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(filepath.FromSlash(u.Path))
+	}
+
+	response, err := http.Get(rawURL) //nolint:gosec // The url is built from GOPROXY
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", rawURL, response.Status)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+// Returns the proxies from GOPROXY which can be fetched from, "direct" and "off" are skipped.
+func goProxies(c *model.CommandConfig) (proxies []string) {
+	goProxy := os.Getenv("GOPROXY")
+	if goProxy == "" {
+		if output, err := exec.Command(model.FirstNonEmpty(c.GoCmd, "go"), "env", "GOPROXY").Output(); err == nil {
+			goProxy = strings.TrimSpace(string(output))
+		}
+	}
+	if goProxy == "" {
+		goProxy = "https://proxy.golang.org,direct"
+	}
+	for _, proxy := range strings.FieldsFunc(goProxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		if proxy = strings.TrimSpace(proxy); proxy != "" && proxy != "direct" && proxy != "off" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return
+}
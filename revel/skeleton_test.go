@@ -0,0 +1,136 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/revel/cmd/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes the file, creating its folder.
+func writeSkeletonFile(t *testing.T, path, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+// Returns a command config for a new application, the fetched skeleton is removed with the test.
+func newSkeletonConfig(t *testing.T) *model.CommandConfig {
+	c := &model.CommandConfig{Index: model.NEW}
+	t.Cleanup(func() {
+		if c.New.SkeletonTempDir != "" {
+			os.RemoveAll(c.New.SkeletonTempDir)
+		}
+	})
+	return c
+}
+
+// Creates a module proxy in a folder with the versions of the module, the latest is the last one.
+func newFileProxy(t *testing.T, modulePath string, versions ...string) string {
+	proxyPath, err := ioutil.TempDir("", "revel-proxy")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(proxyPath) })
+
+	versionsPath := filepath.Join(proxyPath, filepath.FromSlash(modulePath), "@v")
+	for _, version := range versions {
+		info := `{"Version":"` + version + `"}`
+		writeSkeletonFile(t, filepath.Join(versionsPath, version+".info"), info)
+		writeSkeletonFile(t, filepath.Join(proxyPath, filepath.FromSlash(modulePath), "@latest"), info)
+
+		file, err := os.Create(filepath.Join(versionsPath, version+".zip"))
+		assert.Nil(t, err)
+		archive := zip.NewWriter(file)
+		for name, content := range map[string]string{"go.mod": "module " + modulePath + "\n", "basic/conf/app.conf.template": "version=" + version + "\n"} {
+			w, err := archive.Create(modulePath + "@" + version + "/" + name)
+			assert.Nil(t, err)
+			_, err = w.Write([]byte(content))
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, archive.Close())
+		assert.Nil(t, file.Close())
+	}
+	return "file://" + filepath.ToSlash(proxyPath)
+}
+
+func TestSkeletonFromModuleProxy(t *testing.T) {
+	proxy := newFileProxy(t, "example.com/skeletons", "v1.0.0", "v1.1.0")
+	// The proxies which do not have the module are skipped
+	t.Setenv("GOPROXY", "off,file:///revel/missing/proxy,"+proxy+",direct")
+
+	for _, test := range []struct {
+		skeleton, version string
+	}{
+		{"example.com/skeletons@v1.0.0:basic", "v1.0.0"},
+		{"example.com/skeletons@latest:basic", "v1.1.0"},
+	} {
+		c := newSkeletonConfig(t)
+		c.New.SkeletonPath = test.skeleton
+		if !assert.Nil(t, setSkeletonPath(c), test.skeleton) {
+			continue
+		}
+		assert.Equal(t, "basic", filepath.Base(c.New.SkeletonPath))
+		content, err := ioutil.ReadFile(filepath.Join(c.New.SkeletonPath, "conf", "app.conf.template"))
+		assert.Nil(t, err)
+		assert.Equal(t, "version="+test.version+"\n", string(content), test.skeleton)
+	}
+
+	c := newSkeletonConfig(t)
+	c.New.SkeletonPath = "example.com/skeletons@v2.0.0:basic"
+	err := setSkeletonPath(c)
+	assert.True(t, errors.Is(err, ErrSkeletonNotInProxy), "%v", err)
+
+	c = newSkeletonConfig(t)
+	c.New.SkeletonPath = "example.com/skeletons@v1.0.0:missing"
+	assert.True(t, errors.Is(setSkeletonPath(c), ErrNoSkeleton))
+}
+
+func TestGoProxies(t *testing.T) {
+	t.Setenv("GOPROXY", "https://proxy.example.com|file:///proxy, direct,off")
+	assert.Equal(t, []string{"https://proxy.example.com", "file:///proxy"}, goProxies(&model.CommandConfig{}))
+}
+
+// Test that the ref of the url fragment is checked out of the cloned skeleton.
+func TestSkeletonFromGitRef(t *testing.T) {
+	gitCmd, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	repoPath, err := ioutil.TempDir("", "revel-skeleton-repo")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(repoPath) })
+	git := func(args ...string) {
+		cmd := exec.Command(gitCmd, append([]string{"-c", "user.name=revel", "-c", "user.email=revel@example.com"}, args...)...)
+		cmd.Dir = repoPath
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, "git %v: %s", args, output)
+	}
+	appConf := filepath.Join(repoPath, "basic", "conf", "app.conf.template")
+	git("init", "--quiet")
+	writeSkeletonFile(t, appConf, "version=1\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+	git("tag", "v1")
+	writeSkeletonFile(t, appConf, "version=2\n")
+	git("commit", "--quiet", "-am", "second")
+
+	for ref, expected := range map[string]string{"": "version=2\n", "#v1": "version=1\n"} {
+		sp, err := url.Parse("file://" + filepath.ToSlash(repoPath) + ":basic" + ref)
+		assert.Nil(t, err)
+		c := newSkeletonConfig(t)
+		if !assert.Nil(t, newLoadFromGit(c, sp), ref) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(c.New.SkeletonPath, "conf", "app.conf.template"))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, string(content), ref)
+	}
+
+	sp, _ := url.Parse("file://" + filepath.ToSlash(repoPath) + ":basic#v9")
+	assert.NotNil(t, newLoadFromGit(newSkeletonConfig(t), sp), "An unknown ref should fail")
+}
@@ -6,11 +6,14 @@ package main
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -44,6 +47,18 @@ For example, to run all of UserTest:
 or one of UserTest's methods:
 
     revel test outspoken test UserTest.Test1
+
+The results are written to the test-results folder of the application, as an
+html file per suite by default. Use -r json or -r junit (which may be repeated)
+to write results.json or a junit.xml for a CI server instead.
+
+Use --race to build the application with the race detector, and --cover to
+measure the coverage of the application code while the tests run:
+
+    revel test --cover github.com/revel/examples/booking
+
+The coverage is written to test-results/coverage.out, which go tool cover
+reads, and test-results/coverage.html.
 `,
 }
 
@@ -106,6 +121,11 @@ func testApp(c *model.CommandConfig) (err error) {
 
 	app, reverr := harness.Build(c, revelPath)
 	if reverr != nil {
+		// The compile errors are printed with their source by main
+		var sourceError *utils.SourceError
+		if errors.As(reverr, &sourceError) {
+			return sourceError
+		}
 		return utils.NewBuildIfError(reverr, "Error building: ")
 	}
 	var paths []byte
@@ -118,6 +138,12 @@ func testApp(c *model.CommandConfig) (err error) {
 	}
 	cmd := app.Cmd(runMode)
 	cmd.Dir = c.AppPath
+	if c.Test.Cover {
+		cmd.CoverDir = filepath.Join(resultPath, "coverage")
+		if err = os.Mkdir(cmd.CoverDir, 0777); err != nil {
+			return utils.NewBuildError("Failed to create coverage directory ", "path", cmd.CoverDir, "error", err)
+		}
+	}
 
 	cmd.Stderr = io.MultiWriter(cmd.Stderr, file)
 	cmd.Stdout = io.MultiWriter(cmd.Stderr, file)
@@ -126,7 +152,13 @@ func testApp(c *model.CommandConfig) (err error) {
 	if err := cmd.Start(c); err != nil {
 		return utils.NewBuildError("Unable to start server", "error", err)
 	}
-	defer cmd.Kill()
+	defer func() {
+		// The coverage is written when the app exits
+		cmd.Kill()
+		if cmd.CoverDir != "" {
+			writeCoverageReport(c, resultPath, cmd.CoverDir)
+		}
+	}()
 
 	httpAddr := revelPath.HTTPAddr
 	if httpAddr == "" {
@@ -154,7 +186,7 @@ func testApp(c *model.CommandConfig) (err error) {
 	fmt.Println()
 
 	// Run each suite.
-	failedResults, overallSuccess := runTestSuites(revelPath, baseURL, resultPath, testSuites)
+	failedResults, overallSuccess := runTestSuites(revelPath, baseURL, resultPath, testSuites, c.Test.Reports)
 
 	fmt.Println()
 	if overallSuccess {
@@ -177,6 +209,28 @@ func testApp(c *model.CommandConfig) (err error) {
 	return
 }
 
+// Merges the coverage data of the app into coverage.out and renders it to coverage.html.
+func writeCoverageReport(c *model.CommandConfig, resultPath, coverDir string) {
+	profile := filepath.Join(resultPath, "coverage.out")
+	for _, args := range [][]string{
+		{"tool", "covdata", "textfmt", "-i=" + coverDir, "-o=" + profile},
+		{"tool", "cover", "-html=" + profile, "-o=" + filepath.Join(resultPath, "coverage.html")},
+		{"tool", "covdata", "percent", "-i=" + coverDir},
+	} {
+		coverCmd := exec.Command(c.GoCmd, args...)
+		utils.CmdInit(coverCmd, !c.Vendored, c.AppPath)
+		output, err := coverCmd.CombinedOutput()
+		if err != nil {
+			utils.Logger.Errorf("Failed to write the coverage report: %s %s", err, output)
+			return
+		}
+		if args[1] == "covdata" && args[2] == "percent" {
+			fmt.Printf("\nCoverage:\n%s", output)
+		}
+	}
+	fmt.Printf("Coverage report written to file://%s\n", filepath.Join(resultPath, "coverage.html"))
+}
+
 // Outputs the results to a file.
 func writeResultFile(resultPath, name, content string) {
 	if err := ioutil.WriteFile(filepath.Join(resultPath, name), []byte(content), 0666); err != nil {
@@ -263,14 +317,16 @@ func getTestsList(baseURL string) (*[]tests.TestSuiteDesc, error) {
 	return &testSuites, err
 }
 
-// Run the testsuites using the container.
-func runTestSuites(paths *model.RevelContainer, baseURL, resultPath string, testSuites *[]tests.TestSuiteDesc) (*[]tests.TestSuiteResult, bool) {
+// Run the testsuites using the container, the reports are written in the given formats.
+func runTestSuites(paths *model.RevelContainer, baseURL, resultPath string, testSuites *[]tests.TestSuiteDesc, reports []string) (*[]tests.TestSuiteResult, bool) {
 	// We can determine the testsuite location by finding the test module and extracting the data from it
 	resultFilePath := filepath.Join(paths.ModulePathMap["testrunner"].Path, "app", "views", "TestRunner/SuiteResult.html")
 
 	var (
 		overallSuccess = true
 		failedResults  []tests.TestSuiteResult
+		suiteResults   []tests.TestSuiteResult
+		suiteTimes     []time.Duration
 	)
 	for _, suite := range *testSuites {
 		// Print the name of the suite we're running.
@@ -313,6 +369,11 @@ func runTestSuites(paths *model.RevelContainer, baseURL, resultPath string, test
 			failedResults = append(failedResults, suiteResult)
 		}
 		fmt.Printf("%8s%3s%6ds\n", suiteResultStr, suiteAlert, int(time.Since(startTime).Seconds()))
+		suiteResults = append(suiteResults, suiteResult)
+		suiteTimes = append(suiteTimes, time.Since(startTime))
+		if !utils.ContainsString(reports, "html") {
+			continue
+		}
 		// Create the result HTML file.
 		suiteResultFilename := filepath.Join(resultPath,
 			fmt.Sprintf("%s.%s.html", suite.Name, strings.ToLower(suiteResultStr)))
@@ -321,5 +382,70 @@ func runTestSuites(paths *model.RevelContainer, baseURL, resultPath string, test
 		}
 	}
 
+	if utils.ContainsString(reports, "json") {
+		writeJSONReport(resultPath, suiteResults)
+	}
+	if utils.ContainsString(reports, "junit") {
+		writeJUnitReport(resultPath, suiteResults, suiteTimes)
+	}
 	return &failedResults, overallSuccess
 }
+
+// Writes the results of all the suites to results.json.
+func writeJSONReport(resultPath string, suiteResults []tests.TestSuiteResult) {
+	content, err := json.MarshalIndent(suiteResults, "", "  ")
+	if err != nil {
+		utils.Logger.Error("Failed to create json report", "error", err)
+		return
+	}
+	writeResultFile(resultPath, "results.json", string(content))
+}
+
+type (
+	// The JUnit XML report, as read by most CI servers.
+	junitTestSuites struct {
+		XMLName xml.Name          `xml:"testsuites"`
+		Suites  []*junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Time     string           `xml:"time,attr"`
+		Cases    []*junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// Writes the results of all the suites to junit.xml.
+func writeJUnitReport(resultPath string, suiteResults []tests.TestSuiteResult, suiteTimes []time.Duration) {
+	report := &junitTestSuites{}
+	for i, suiteResult := range suiteResults {
+		suite := &junitTestSuite{Name: suiteResult.Name, Tests: len(suiteResult.Results), Time: fmt.Sprintf("%.3f", suiteTimes[i].Seconds())}
+		for _, result := range suiteResult.Results {
+			testCase := &junitTestCase{Name: result.Name, ClassName: suiteResult.Name}
+			if !result.Passed {
+				suite.Failures++
+				testCase.Failure = &junitFailure{Message: result.ErrorSummary}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+	content, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		utils.Logger.Error("Failed to create junit report", "error", err)
+		return
+	}
+	writeResultFile(resultPath, "junit.xml", xml.Header+string(content))
+}
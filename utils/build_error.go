@@ -3,8 +3,10 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/revel/cmd/logger"
 )
@@ -71,7 +73,7 @@ func NewCompileError(importPath, errorLink string, err error) *SourceError {
 
 		errorMatch = append(errorMatch, errorMatch[3])
 
-		Logger.Error("Build errors", "errors", err)
+		Logger.Debug("Build errors", "file", string(errorMatch[1]))
 	}
 
 	// Read the source for the offending file.
@@ -79,6 +81,7 @@ func NewCompileError(importPath, errorLink string, err error) *SourceError {
 		relFilename  = string(errorMatch[1]) // e.g. "src/revel/sample/app/controllers/app.go"
 		absFilename  = relFilename
 		line, _      = strconv.Atoi(string(errorMatch[2]))
+		column, _    = strconv.Atoi(strings.TrimSuffix(string(errorMatch[3]), ":"))
 		description  = string(errorMatch[4])
 		compileError = &SourceError{
 			SourceType:  "Go code",
@@ -86,9 +89,12 @@ func NewCompileError(importPath, errorLink string, err error) *SourceError {
 			Path:        relFilename,
 			Description: description,
 			Line:        line,
+			Column:      column,
 		}
 	)
 
+	compileError.AbsPath, _ = filepath.Abs(relFilename)
+
 	// errorLink := paths.Config.StringDefault("error.link", "")
 
 	if errorLink != "" {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -47,3 +48,12 @@ func ReducedEnv(addGoPath bool) []string {
 	}
 	return env
 }
+
+// ShellCmd returns the command which runs the command line in the shell, "sh -c" or "cmd /C"
+// on windows, so the line may use quotes, pipes and variables.
+func ShellCmd(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -2,20 +2,28 @@ package utils
 
 import (
 	"fmt"
+	"html"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/revel/cmd/logger"
 )
 
 // The error is a wrapper for the.
 type (
 	SourceError struct {
-		SourceType               string   // The type of source that failed to build.
-		Title, Path, Description string   // Description of the error, as presented to the user.
-		Line, Column             int      // Where the error was encountered.
-		SourceLines              []string // The entire source file, split into lines.
-		Stack                    string   // The raw stack trace string from debug.Stack().
-		MetaError                string   // Error that occurred producing the error page.
-		Link                     string   // A configurable link to wrap the error source in
+		SourceType               string      // The type of source that failed to build.
+		Title, Path, Description string      // Description of the error, as presented to the user.
+		Line, Column             int         // Where the error was encountered.
+		SourceLines              []string    // The entire source file, split into lines.
+		Stack                    string      // The raw stack trace string from debug.Stack().
+		MetaError                string      // Error that occurred producing the error page.
+		AbsPath                  string      // The absolute path of the file, if it is known
+		URL                      string      // The link to the error source made from "error.link"
+		Link                     string      // The HTML of the link to the error source
+		Panic                    *PanicTrace // The parsed stack, when the error is a panic of the app
 	}
 	SourceLine struct {
 		Source  string
@@ -34,12 +42,44 @@ func NewError(source, title, path, description string) *SourceError {
 	}
 }
 
-// Creates a link based on the configuration setting "errors.link".
-func (e *SourceError) SetLink(errorLink string) {
-	errorLink = strings.ReplaceAll(errorLink, "{{Path}}", e.Path)
-	errorLink = strings.ReplaceAll(errorLink, "{{Line}}", strconv.Itoa(e.Line))
+// ErrorLinkPresets are the links of the editors which "error.link" selects by name.
+var ErrorLinkPresets = map[string]string{
+	"vscode": "vscode://file{{AbsPath}}:{{Line}}:{{Column}}",
+	"idea":   "idea://open?file={{AbsPath}}&line={{Line}}&column={{Column}}",
+	"subl":   "subl://open?url=file://{{AbsPath}}&line={{Line}}&column={{Column}}",
+	"file":   "file://{{AbsPath}}",
+}
 
-	e.Link = "<a href=" + errorLink + ">" + e.Path + ":" + strconv.Itoa(e.Line) + "</a>"
+// ErrorLinkURL returns the link to the position in the file for the "error.link" setting, which
+// is either the name of a preset or a template with the {{Path}}, {{AbsPath}}, {{Line}} and
+// {{Column}} placeholders. The absolute path is resolved from the path if it is empty, it has
+// forward slashes and starts with one.
+func ErrorLinkURL(errorLink, path, absPath string, line, column int) string {
+	if preset, found := ErrorLinkPresets[errorLink]; found {
+		errorLink = preset
+	}
+	if absPath == "" {
+		absPath, _ = filepath.Abs(path)
+	}
+	// Windows paths start with the drive, the URLs need the slash before it
+	if absPath = filepath.ToSlash(absPath); !strings.HasPrefix(absPath, "/") {
+		absPath = "/" + absPath
+	}
+	if column < 1 {
+		column = 1
+	}
+	return strings.NewReplacer(
+		"{{Path}}", path,
+		"{{AbsPath}}", absPath,
+		"{{Line}}", strconv.Itoa(line),
+		"{{Column}}", strconv.Itoa(column),
+	).Replace(errorLink)
+}
+
+// Creates a link based on the configuration setting "error.link".
+func (e *SourceError) SetLink(errorLink string) {
+	e.URL = ErrorLinkURL(errorLink, e.Path, e.AbsPath, e.Line, e.Column)
+	e.Link = `<a href="` + html.EscapeString(e.URL) + `">` + html.EscapeString(e.Path+":"+strconv.Itoa(e.Line)) + "</a>"
 }
 
 // Error method constructs a plaintext version of the error, taking
@@ -49,13 +89,74 @@ func (e *SourceError) Error() string {
 	if e == nil {
 		panic("opps")
 	}
-	loc := ""
-	if e.Path != "" {
-		line := ""
-		if e.Line != 0 {
-			line = fmt.Sprintf(":%d", e.Line)
+	return e.format(e.location())
+}
+
+// TerminalString returns the error for the terminal: the title and description, the location,
+// which is a hyperlink to the error source if "error.link" is set, and the source around the line
+// with a caret under the column. It is coloured unless the standard output is not a terminal or
+// NO_COLOR is set.
+func (e *SourceError) TerminalString() string {
+	noColor := logger.TerminalNoColor(os.Stdout)
+	b := &strings.Builder{}
+	title := e.Title
+	if title == "" {
+		title = "Error"
+	}
+	fmt.Fprintf(b, "%s %s\n", logger.Colorize(title+":", "1;31", noColor), e.Description)
+	if location := e.location(); location != "" {
+		if e.Line > 0 && e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
 		}
-		loc = fmt.Sprintf("(in %s%s)", e.Path, line)
+		fmt.Fprintf(b, "  %s %s\n", logger.Colorize("-->", "34", noColor), Hyperlink(e.URL, logger.Colorize(location, "36", noColor)))
+	}
+
+	lines := e.ContextSource()
+	if len(lines) > 0 {
+		width := len(strconv.Itoa(lines[len(lines)-1].Line))
+		for _, line := range lines {
+			number := fmt.Sprintf("%*d |", width, line.Line)
+			if !line.IsError {
+				fmt.Fprintf(b, "  %s %s\n", logger.Colorize(number, "2", noColor), logger.Colorize(line.Source, "2", noColor))
+				continue
+			}
+			fmt.Fprintf(b, "%s %s %s\n", logger.Colorize(">", "1;31", noColor), logger.Colorize(number, "1", noColor), line.Source)
+			if e.Column > 0 {
+				fmt.Fprintf(b, "  %*s | %s\n", width, "", logger.Colorize(caretIndent(line.Source, e.Column)+"^", "1;31", noColor))
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Returns the indent of the caret under the column of the line, the tabs are kept so the caret
+// lines up with the source.
+func caretIndent(source string, column int) string {
+	if column-1 < len(source) {
+		source = source[:column-1]
+	}
+	indent := []rune(source)
+	for i, char := range indent {
+		if char != '\t' {
+			indent[i] = ' '
+		}
+	}
+	return string(indent)
+}
+
+// Returns the path and line of the error, if it has a path.
+func (e *SourceError) location() string {
+	if e.Path == "" || e.Line == 0 {
+		return e.Path
+	}
+	return fmt.Sprintf("%s:%d", e.Path, e.Line)
+}
+
+// Formats the error with the location.
+func (e *SourceError) format(location string) string {
+	loc := ""
+	if location != "" {
+		loc = fmt.Sprintf("(in %s)", location)
 	}
 	header := loc
 	if e.Title != "" {
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorLinkURL(t *testing.T) {
+	cwd, err := os.Getwd()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, test := range []struct {
+		errorLink, path, absPath string
+		line, column             int
+		url                      string
+	}{
+		{"vscode", "app/app.go", "/home/user/app/app.go", 12, 5, "vscode://file/home/user/app/app.go:12:5"},
+		{"idea", "app/app.go", "/home/user/app/app.go", 12, 5, "idea://open?file=/home/user/app/app.go&line=12&column=5"},
+		{"subl", "app/app.go", "/home/user/app/app.go", 12, 5, "subl://open?url=file:///home/user/app/app.go&line=12&column=5"},
+		{"file", "app/app.go", "/home/user/app/app.go", 12, 5, "file:///home/user/app/app.go"},
+		// The column is at least 1
+		{"vscode", "app/app.go", "/home/user/app/app.go", 12, 0, "vscode://file/home/user/app/app.go:12:1"},
+		{"http://localhost/edit?f={{Path}}&l={{Line}}&c={{Column}}&a={{AbsPath}}", "app/app.go", "/src/app/app.go", 3, 4,
+			"http://localhost/edit?f=app/app.go&l=3&c=4&a=/src/app/app.go"},
+		{"editor {{Path}}", "app/app.go", "", 1, 1, "editor app/app.go"},
+		// Windows paths get a slash before the drive
+		{"vscode", "app/app.go", "C:/Users/user/app/app.go", 7, 2, "vscode://file/C:/Users/user/app/app.go:7:2"},
+		{"file", "app/app.go", "C:/Users/user/app/app.go", 7, 2, "file:///C:/Users/user/app/app.go"},
+		// The absolute path is resolved from the path
+		{"file", "app.go", "", 1, 1, "file://" + filepath.ToSlash(filepath.Join(cwd, "app.go"))},
+	} {
+		assert.Equal(t, test.url, ErrorLinkURL(test.errorLink, test.path, test.absPath, test.line, test.column), test.errorLink)
+	}
+}
+
+func TestSetLink(t *testing.T) {
+	e := &SourceError{Path: `app/<b>.go`, AbsPath: "/app/<b>.go", Line: 3, Column: 2}
+	e.SetLink("http://localhost/?f={{AbsPath}}&l={{Line}}")
+	assert.Equal(t, "http://localhost/?f=/app/<b>.go&l=3", e.URL)
+	assert.Equal(t, `<a href="http://localhost/?f=/app/&lt;b&gt;.go&amp;l=3">app/&lt;b&gt;.go:3</a>`, e.Link)
+}
+
+func TestHyperlink(t *testing.T) {
+	defer func(hyperlinks bool) { terminalHyperlinks = hyperlinks }(terminalHyperlinks)
+
+	terminalHyperlinks = false
+	assert.Equal(t, "app.go:3", Hyperlink("file:///app.go", "app.go:3"))
+
+	terminalHyperlinks = true
+	assert.Equal(t, "\x1b]8;;file:///app.go\x1b\\app.go:3\x1b]8;;\x1b\\", Hyperlink("file:///app.go", "app.go:3"))
+	assert.Equal(t, "app.go:3", Hyperlink("", "app.go:3"))
+}
+
+func TestNewCompileError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-compile-error")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	cwd, err := os.Getwd()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.Chdir(cwd)
+	assert.Nil(t, os.Chdir(dir))
+	assert.Nil(t, ioutil.WriteFile("app.go", []byte("package app\n\nfunc f() {\n\tx\n}\n"), 0644))
+	dir, _ = os.Getwd()
+
+	compileError := NewCompileError("example.com/app", "vscode", errors.New("# example.com/app\napp.go:4:2: undefined: x\n"))
+	assert.Equal(t, "app.go", compileError.Path)
+	assert.Equal(t, filepath.Join(dir, "app.go"), compileError.AbsPath)
+	assert.Equal(t, 4, compileError.Line)
+	assert.Equal(t, 2, compileError.Column)
+	assert.Equal(t, "undefined: x", compileError.Description)
+	assert.Equal(t, "vscode://file"+filepath.ToSlash(filepath.Join(dir, "app.go"))+":4:2", compileError.URL)
+	assert.Equal(t, "\tx", compileError.SourceLines[3])
+}
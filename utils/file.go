@@ -2,6 +2,7 @@ package utils
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
 	"errors"
@@ -161,6 +162,12 @@ func PanicOnError(err error, msg string) {
 // Additionally, the trailing ".template" is stripped from the file name.
 // Also, dot files and dot directories are skipped.
 func CopyDir(destDir, srcDir string, data map[string]interface{}) error {
+	return CopyDirFiltered(destDir, srcDir, data, nil)
+}
+
+// CopyDirFiltered is CopyDir, the files and folders the filter returns true for are skipped.
+// The filter is called with the path relative to the source folder.
+func CopyDirFiltered(destDir, srcDir string, data map[string]interface{}, skip func(relPath string) (bool, error)) error {
 	if !DirExists(srcDir) {
 		return nil
 	}
@@ -178,6 +185,18 @@ func CopyDir(destDir, srcDir string, data map[string]interface{}) error {
 			return nil
 		}
 
+		if skip != nil && relSrcPath != "" {
+			skipped, err := skip(relSrcPath)
+			if err != nil {
+				return err
+			}
+			if skipped && info.IsDir() {
+				return filepath.SkipDir
+			} else if skipped {
+				return nil
+			}
+		}
+
 		// Create a subdirectory if necessary.
 		if info.IsDir() {
 			err := os.MkdirAll(filepath.Join(destDir, relSrcPath), 0777)
@@ -304,6 +323,123 @@ func TarGzDir(destFilename, srcDir string) (name string, err error) {
 	return zipFile.Name(), err
 }
 
+// ExtractArchive extracts the .tar.gz, .tgz or .zip archive into the destination folder.
+// Entries which would be written outside of the destination folder are rejected.
+func ExtractArchive(destDir, archivePath string) (err error) {
+	lowerPath := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lowerPath, ".zip"):
+		return extractZip(destDir, archivePath)
+	case strings.HasSuffix(lowerPath, ".tar.gz"), strings.HasSuffix(lowerPath, ".tgz"):
+		return extractTarGz(destDir, archivePath)
+	}
+	return NewBuildError("Unsupported archive type", "file", archivePath)
+}
+
+// Extracts the zip archive into the folder.
+func extractZip(destDir, archivePath string) (err error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return NewBuildIfError(err, "Failed to open archive", "file", archivePath)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	for _, file := range reader.File {
+		destPath, err := archiveEntryPath(destDir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err = os.MkdirAll(destPath, 0777); err != nil {
+				return NewBuildIfError(err, "Failed to create directory", "path", destPath)
+			}
+			continue
+		}
+		entry, err := file.Open()
+		if err != nil {
+			return NewBuildIfError(err, "Failed to read archive entry", "file", archivePath, "entry", file.Name)
+		}
+		err = writeArchiveEntry(destPath, entry, file.Mode())
+		_ = entry.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// Extracts the gzipped tar archive into the folder.
+func extractTarGz(destDir, archivePath string) (err error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return NewBuildIfError(err, "Failed to open archive", "file", archivePath)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return NewBuildIfError(err, "Failed to read archive", "file", archivePath)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return NewBuildIfError(err, "Failed to read archive", "file", archivePath)
+		}
+		destPath, err := archiveEntryPath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(destPath, 0777); err != nil {
+				return NewBuildIfError(err, "Failed to create directory", "path", destPath)
+			}
+		case tar.TypeReg:
+			if err = writeArchiveEntry(destPath, tarReader, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		default:
+			Logger.Info("Skipping archive entry", "entry", header.Name, "type", header.Typeflag)
+		}
+	}
+}
+
+// Returns the path in the destination folder for the archive entry.
+func archiveEntryPath(destDir, name string) (string, error) {
+	destPath := filepath.Join(destDir, filepath.FromSlash(name))
+	if destPath != filepath.Clean(destDir) && !strings.HasPrefix(destPath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", NewBuildError("Archive entry outside of destination", "entry", name)
+	}
+	return destPath, nil
+}
+
+// Writes the archive entry to the file, creating the folder if needed.
+func writeArchiveEntry(destPath string, reader io.Reader, mode os.FileMode) (err error) {
+	if err = os.MkdirAll(filepath.Dir(destPath), 0777); err != nil {
+		return NewBuildIfError(err, "Failed to create directory", "path", filepath.Dir(destPath))
+	}
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return NewBuildIfError(err, "Failed to create file", "file", destPath)
+	}
+	if _, err = io.Copy(destFile, reader); err != nil {
+		_ = destFile.Close()
+		return NewBuildIfError(err, "Failed to extract file", "file", destPath)
+	}
+	if err = destFile.Close(); err != nil {
+		return NewBuildIfError(err, "Failed to close file", "file", destPath)
+	}
+	return
+}
+
 // Return true if the file exists.
 func Exists(filename string) bool {
 	_, err := os.Stat(filename)
@@ -333,11 +469,19 @@ func FindSrcPaths(appPath string, packageList []string, packageResolver func(pkg
 	sourcePathsmap, missingList, err := findSrcPaths(appPath, packageList)
 	if err != nil && packageResolver != nil || len(missingList) > 0 {
 		Logger.Info("Failed to find package, attempting to call resolver for missing packages", "missing packages", missingList)
+		// Every package is tried so the error lists all that could not be resolved
+		var unresolved []string
 		for _, item := range missingList {
-			if err = packageResolver(item); err != nil {
-				return
+			if resolveErr := packageResolver(item); resolveErr != nil {
+				unresolved = append(unresolved, item)
+				err = resolveErr
 			}
 		}
+		if len(unresolved) > 1 {
+			return sourcePathsmap, fmt.Errorf("unable to resolve %s: %w", strings.Join(unresolved, ", "), err)
+		} else if len(unresolved) > 0 {
+			return
+		}
 		sourcePathsmap, missingList, err = findSrcPaths(appPath, packageList)
 	}
 	if err != nil && len(missingList) > 0 {
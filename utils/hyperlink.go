@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// True if the standard output is a terminal which shows hyperlinks. Terminals which do not
+// support them ignore the escape sequence.
+var terminalHyperlinks = isatty.IsTerminal(os.Stdout.Fd()) && os.Getenv("TERM") != "dumb"
+
+// Hyperlink returns the text linked to the url with an OSC 8 escape sequence, which the terminal
+// shows as a clickable link. The text is returned as is if the url is empty or the standard output
+// is not a terminal.
+func Hyperlink(url, text string) string {
+	if url == "" || !terminalHyperlinks {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
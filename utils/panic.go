@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// PanicTrace is the output of a Go program which panicked, parsed into goroutines.
+	PanicTrace struct {
+		Message    string       // The panic message, e.g. "runtime error: index out of range"
+		Goroutines []*Goroutine // The goroutines in the output, the first one panicked
+	}
+
+	// Goroutine is the trace of a goroutine in the output of a panic.
+	Goroutine struct {
+		ID     int
+		State  string // e.g. "running"
+		Frames []*StackFrame
+	}
+
+	// StackFrame is a function call in the trace of a goroutine.
+	StackFrame struct {
+		Function string // e.g. "example.com/app/controllers.App.Index" or "created by main.main"
+		File     string // The absolute path of the file
+		Line     int
+		App      bool   // True if the file is application code
+		Path     string // The path shown for the file, relative to the application for its code
+		Link     string // The error.link of the frame
+		Source   string // The source line, for application code
+	}
+)
+
+var (
+	goroutineRegex = regexp.MustCompile(`^goroutine (\d+) \[([^\]]*)\]:$`)
+	frameFileRegex = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// ParsePanic parses the output of a Go program which panicked or failed with a fatal error,
+// nil is returned if the output contains no panic.
+func ParsePanic(output string) *PanicTrace {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	trace := &PanicTrace{}
+	var message []string
+	var goroutine *Goroutine
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if match := goroutineRegex.FindStringSubmatch(line); match != nil {
+			id, _ := strconv.Atoi(match[1])
+			goroutine = &Goroutine{ID: id, State: match[2]}
+			trace.Goroutines = append(trace.Goroutines, goroutine)
+			continue
+		}
+		if goroutine == nil {
+			// The panics of a recovered panic are indented
+			message = append(message, strings.TrimPrefix(line, "\t"))
+			continue
+		}
+		// A frame is the function followed by its file
+		if line == "" || strings.HasPrefix(line, "\t") || i+1 == len(lines) {
+			continue
+		}
+		match := frameFileRegex.FindStringSubmatch(lines[i+1])
+		if match == nil {
+			continue
+		}
+		lineNumber, _ := strconv.Atoi(match[2])
+		goroutine.Frames = append(goroutine.Frames, &StackFrame{
+			Function: frameFunction(line),
+			File:     match[1],
+			Line:     lineNumber,
+			Path:     match[1],
+		})
+		i++
+	}
+
+	trace.Message = strings.TrimSpace(strings.Join(message, "\n"))
+	trace.Message = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(trace.Message, "panic: "), "fatal error: "))
+	return trace
+}
+
+// Returns the function of the frame without its arguments, e.g. "main.(*T).M" for
+// "main.(*T).M(0xc000010000, 0x1)".
+func frameFunction(line string) string {
+	if strings.HasSuffix(line, ")") {
+		if index := strings.LastIndex(line, "("); index > 0 {
+			return line[:index]
+		}
+	}
+	return line
+}
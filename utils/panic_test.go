@@ -0,0 +1,117 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/revel/cmd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePanic(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		output string
+		trace  *utils.PanicTrace
+	}{
+		{
+			name:   "no panic",
+			output: "INFO  12:00:00 app run.go:32: Running revel server\n",
+		},
+		{
+			name: "panic",
+			output: "INFO  12:00:00 app run.go:32: Running revel server\n" +
+				"panic: runtime error: index out of range [3] with length 3\n" +
+				"\n" +
+				"goroutine 1 [running]:\n" +
+				"example.com/app/app/controllers.App.Index({0xc000010000})\n" +
+				"\t/home/user/app/app/controllers/app.go:12 +0x1d\n" +
+				"main.main()\n" +
+				"\t/home/user/app/app/tmp/main.go:20 +0x45\n" +
+				"exit status 2\n",
+			trace: &utils.PanicTrace{
+				Message: "runtime error: index out of range [3] with length 3",
+				Goroutines: []*utils.Goroutine{{ID: 1, State: "running", Frames: []*utils.StackFrame{
+					{Function: "example.com/app/app/controllers.App.Index", File: "/home/user/app/app/controllers/app.go", Line: 12, Path: "/home/user/app/app/controllers/app.go"},
+					{Function: "main.main", File: "/home/user/app/app/tmp/main.go", Line: 20, Path: "/home/user/app/app/tmp/main.go"},
+				}}},
+			},
+		},
+		{
+			name: "fatal error",
+			output: "fatal error: all goroutines are asleep - deadlock!\r\n" +
+				"\r\n" +
+				"goroutine 1 [chan receive]:\r\n" +
+				"main.main()\r\n" +
+				"\tC:/app/main.go:8 +0x28\r\n",
+			trace: &utils.PanicTrace{
+				Message: "all goroutines are asleep - deadlock!",
+				Goroutines: []*utils.Goroutine{{ID: 1, State: "chan receive", Frames: []*utils.StackFrame{
+					{Function: "main.main", File: "C:/app/main.go", Line: 8, Path: "C:/app/main.go"},
+				}}},
+			},
+		},
+		{
+			name: "goroutines",
+			output: "panic: boom\n" +
+				"\n" +
+				"goroutine 7 [running]:\n" +
+				"main.worker(0x1, 0x2)\n" +
+				"\t/app/worker.go:10 +0x1\n" +
+				"created by main.main in goroutine 1\n" +
+				"\t/app/main.go:5 +0x2\n" +
+				"\n" +
+				"goroutine 1 [sleep, 2 minutes]:\n" +
+				"time.Sleep(0x3b9aca00)\n" +
+				"\t/usr/local/go/src/runtime/time.go:195\n" +
+				"main.main()\n" +
+				"\t/app/main.go:6 +0x3\n",
+			trace: &utils.PanicTrace{
+				Message: "boom",
+				Goroutines: []*utils.Goroutine{
+					{ID: 7, State: "running", Frames: []*utils.StackFrame{
+						{Function: "main.worker", File: "/app/worker.go", Line: 10, Path: "/app/worker.go"},
+						{Function: "created by main.main in goroutine 1", File: "/app/main.go", Line: 5, Path: "/app/main.go"},
+					}},
+					{ID: 1, State: "sleep, 2 minutes", Frames: []*utils.StackFrame{
+						{Function: "time.Sleep", File: "/usr/local/go/src/runtime/time.go", Line: 195, Path: "/usr/local/go/src/runtime/time.go"},
+						{Function: "main.main", File: "/app/main.go", Line: 6, Path: "/app/main.go"},
+					}},
+				},
+			},
+		},
+		{
+			name: "recovered",
+			output: "panic: first [recovered]\n" +
+				"\tpanic: second\n" +
+				"\n" +
+				"goroutine 1 [running]:\n" +
+				"panic({0x4a1e20?, 0x4e2b30?})\n" +
+				"\t/usr/local/go/src/runtime/panic.go:770 +0x132\n" +
+				"main.main.func1()\n" +
+				"\t/app/main.go:9 +0x25\n",
+			trace: &utils.PanicTrace{
+				Message: "first [recovered]\npanic: second",
+				Goroutines: []*utils.Goroutine{{ID: 1, State: "running", Frames: []*utils.StackFrame{
+					{Function: "panic", File: "/usr/local/go/src/runtime/panic.go", Line: 770, Path: "/usr/local/go/src/runtime/panic.go"},
+					{Function: "main.main.func1", File: "/app/main.go", Line: 9, Path: "/app/main.go"},
+				}}},
+			},
+		},
+		{
+			name: "repanicked",
+			output: "panic: first [recovered, repanicked]\n" +
+				"\n" +
+				"goroutine 1 [running]:\n" +
+				"main.main()\n" +
+				"\t/app/main.go:9 +0x25\n",
+			trace: &utils.PanicTrace{
+				Message: "first [recovered, repanicked]",
+				Goroutines: []*utils.Goroutine{{ID: 1, State: "running", Frames: []*utils.StackFrame{
+					{Function: "main.main", File: "/app/main.go", Line: 9, Path: "/app/main.go"},
+				}}},
+			},
+		},
+	} {
+		assert.Equal(t, test.trace, utils.ParsePanic(test.output), test.name)
+	}
+}
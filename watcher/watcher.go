@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -36,7 +37,7 @@ type Watcher struct {
 	// Parallel arrays of watcher/listener pairs.
 	watchers            []*fsnotify.Watcher
 	listeners           []Listener
-	forceRefresh        bool
+	forceRefresh        int32 // Set when the next Notify refreshes the listeners, accessed atomically
 	eagerRefresh        bool
 	serial              bool
 	lastError           int
@@ -52,7 +53,7 @@ type Watcher struct {
 // Creates a new watched based on the container.
 func NewWatcher(paths *model.RevelContainer, eagerRefresh bool) *Watcher {
 	return &Watcher{
-		forceRefresh:    true,
+		forceRefresh:    1,
 		lastError:       -1,
 		paths:           paths,
 		refreshInterval: time.Duration(paths.Config.IntDefault("watch.rebuild.delay", 1000)) * time.Millisecond,
@@ -176,6 +177,11 @@ func (w *Watcher) NotifyWhenUpdated(listener Listener, watcher *fsnotify.Watcher
 	}
 }
 
+// ForceRefresh makes the next Notify refresh the listeners, even without change events.
+func (w *Watcher) ForceRefresh() {
+	atomic.StoreInt32(&w.forceRefresh, 1)
+}
+
 // Notify causes the watcher to forward any change events to listeners.
 // It returns the first (if any) error returned.
 func (w *Watcher) Notify() *utils.SourceError {
@@ -205,9 +211,10 @@ func (w *Watcher) Notify() *utils.SourceError {
 			break
 		}
 
+		force := atomic.LoadInt32(&w.forceRefresh) == 1
 		utils.Logger.Info("Watcher:Notify refresh state", "Current Index", i, " last error index", w.lastError,
-			"force", w.forceRefresh, "refresh", refresh, "lastError", w.lastError == i)
-		if w.forceRefresh || refresh || w.lastError == i {
+			"force", force, "refresh", refresh, "lastError", w.lastError == i)
+		if force || refresh || w.lastError == i {
 			var err *utils.SourceError
 			if w.serial {
 				err = listener.Refresh()
@@ -216,12 +223,12 @@ func (w *Watcher) Notify() *utils.SourceError {
 			}
 			if err != nil {
 				w.lastError = i
-				w.forceRefresh = true
+				atomic.StoreInt32(&w.forceRefresh, 1)
 				return err
 			}
 
 			w.lastError = -1
-			w.forceRefresh = false
+			atomic.StoreInt32(&w.forceRefresh, 0)
 		}
 	}
 
@@ -238,7 +245,7 @@ func (w *Watcher) notifyInProcess(listener Listener) (err *utils.SourceError) {
 		w.timerMutex.Lock()
 		defer w.timerMutex.Unlock()
 		// If we are in the process of a rebuild, forceRefresh will always be true
-		w.forceRefresh = true
+		atomic.StoreInt32(&w.forceRefresh, 1)
 		if w.refreshTimer != nil {
 			utils.Logger.Info("Found existing timer running, resetting")
 			w.refreshTimer.Reset(w.refreshInterval)
@@ -273,7 +280,7 @@ func (w *Watcher) notifyInProcess(listener Listener) (err *utils.SourceError) {
 		utils.Logger.Info("Watcher: Recording error last build, setting rebuild on", "error", err)
 	} else {
 		w.lastError = -1
-		w.forceRefresh = false
+		atomic.StoreInt32(&w.forceRefresh, 0)
 	}
 	utils.Logger.Info("Rebuilt, result", "error", err)
 	return
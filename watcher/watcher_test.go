@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/revel/cmd/model"
+	"github.com/revel/cmd/utils"
+	"github.com/revel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Counts the refreshes.
+type countingListener struct {
+	refreshes int32
+}
+
+func (l *countingListener) Refresh() *utils.SourceError {
+	atomic.AddInt32(&l.refreshes, 1)
+	return nil
+}
+
+// Test that a forced refresh is seen by the next notify, also when it is forced while notifying.
+func TestForceRefresh(t *testing.T) {
+	paths := &model.RevelContainer{Config: config.NewContext()}
+	paths.Config.SetOption("watch.rebuild.delay", "1")
+	w := NewWatcher(paths, false)
+	fsWatcher, err := fsnotify.NewWatcher()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer fsWatcher.Close()
+	listener := &countingListener{}
+	w.watchers = append(w.watchers, fsWatcher)
+	w.listeners = append(w.listeners, listener)
+
+	assert.Nil(t, w.Notify())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&listener.refreshes), "The first notify should refresh")
+	assert.Nil(t, w.Notify())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&listener.refreshes), "Nothing changed")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			w.ForceRefresh()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, w.Notify())
+	}
+	<-done
+
+	w.ForceRefresh()
+	before := atomic.LoadInt32(&listener.refreshes)
+	assert.Nil(t, w.Notify())
+	assert.Equal(t, before+1, atomic.LoadInt32(&listener.refreshes))
+}